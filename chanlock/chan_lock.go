@@ -1,7 +1,15 @@
 // Package chanlock 基于chan实现trylock乐观锁
+// 等待加锁的goroutine按照先来先服务(FIFO)的顺序获得锁，避免长时间等待的goroutine饿死
 package chanlock
 
 import (
+	"bytes"
+	"container/list"
+	"context"
+	"log"
+	"runtime"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -10,24 +18,117 @@ var DefaultLockTimeout = 10 * time.Millisecond
 
 // ChanLock chan lock
 type ChanLock struct {
-	ch chan struct{} // 空结构体
+	mu      sync.Mutex
+	locked  bool
+	waiters list.List // 等待加锁的goroutine，元素为chan struct{}，解锁时关闭chan把锁交给它
+
+	debug     bool                              // 是否记录持有锁的goroutine
+	threshold time.Duration                     // 持有锁超过该时间就报告
+	reporter  func(o Owner, held time.Duration) // 报告长时间持有锁的回调
+	owner     *Owner                            // 当前持有锁的goroutine，debug模式下才记录
+	timer     *time.Timer                       // 检测长时间持有锁的定时器
+}
+
+// Owner 持有锁的goroutine信息
+type Owner struct {
+	Goroutine int64     // goroutine id
+	Stack     []byte    // 加锁时的调用栈
+	Since     time.Time // 加锁的时间
+}
+
+// Option 采用func Option功能模式为ChanLock添加参数
+type Option func(l *ChanLock)
+
+// WithDebug 开启debug模式，记录持有锁的goroutine和调用栈
+// threshold大于0时，持有锁超过threshold会调用reporter报告
+func WithDebug(threshold time.Duration) Option {
+	return func(l *ChanLock) {
+		l.debug = true
+		l.threshold = threshold
+	}
+}
+
+// WithReporter 设置报告长时间持有锁的回调，默认打印日志
+func WithReporter(fn func(o Owner, held time.Duration)) Option {
+	return func(l *ChanLock) {
+		l.reporter = fn
+	}
 }
 
 // NewChanLock 实例化一个通道空结构体锁对象
-func NewChanLock() *ChanLock {
-	return &ChanLock{
-		ch: make(chan struct{}, 1), // 有缓冲通道
+func NewChanLock(opts ...Option) *ChanLock {
+	l := &ChanLock{}
+	for _, o := range opts {
+		o(l)
 	}
+
+	if l.reporter == nil {
+		l.reporter = defaultReporter
+	}
+
+	return l
 }
 
-// Lock 通道加锁,如果无法放入ch，该方法就会阻塞，直到ch通道锁释放为止
+// Lock 通道加锁,如果锁已经被持有，该方法就会阻塞，直到锁释放为止
 func (l *ChanLock) Lock() {
-	l.ch <- struct{}{} // 这里是一个空结构体
+	_ = l.LockContext(context.Background())
 }
 
-// Unlock 实现通道解锁
+// LockContext 加锁，直到获得锁或者ctx取消为止
+// 获得锁返回nil，ctx取消返回ctx.Err()，此时没有持有锁
+func (l *ChanLock) LockContext(ctx context.Context) error {
+	l.mu.Lock()
+	if !l.locked && l.waiters.Len() == 0 {
+		l.locked = true
+		l.acquired()
+		l.mu.Unlock()
+		return nil
+	}
+
+	// 排队等待，解锁时按照先来先服务的顺序把锁交给等待者
+	ch := make(chan struct{})
+	elem := l.waiters.PushBack(ch)
+	l.mu.Unlock()
+
+	select {
+	case <-ch:
+		l.mu.Lock()
+		l.acquired()
+		l.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-ch:
+			// ctx取消的同时获得了锁，需要把锁交给下一个等待者
+			l.mu.Unlock()
+			l.Unlock()
+		default:
+			l.waiters.Remove(elem)
+			l.mu.Unlock()
+		}
+
+		return ctx.Err()
+	}
+}
+
+// Unlock 实现通道解锁，如果有等待者，直接把锁交给最早的等待者
 func (l *ChanLock) Unlock() {
-	<-l.ch
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.locked {
+		panic("chanlock: unlock of unlocked lock")
+	}
+
+	l.released()
+	if front := l.waiters.Front(); front != nil {
+		l.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+
+	l.locked = false
 }
 
 // TryLock 乐观锁实现
@@ -46,15 +147,69 @@ func (l *ChanLock) tryLockTimeout(timeout time.Duration) bool {
 		timeout = DefaultLockTimeout
 	}
 
-	ticker := time.NewTicker(timeout)
-	defer ticker.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	for {
-		select {
-		case l.ch <- struct{}{}:
-			return true
-		case <-ticker.C:
-			return false
-		}
+	return l.LockContext(ctx) == nil
+}
+
+// Owner 返回当前持有锁的goroutine信息，没有持有锁或者没有开启debug模式时返回nil
+func (l *ChanLock) Owner() *Owner {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.owner == nil {
+		return nil
+	}
+
+	o := *l.owner
+	return &o
+}
+
+// acquired 获得锁之后记录持有锁的goroutine，调用时需要持有l.mu
+func (l *ChanLock) acquired() {
+	if !l.debug {
+		return
+	}
+
+	stack := make([]byte, 4096)
+	stack = stack[:runtime.Stack(stack, false)]
+	o := &Owner{
+		Goroutine: goroutineID(stack),
+		Stack:     stack,
+		Since:     time.Now(),
 	}
+
+	l.owner = o
+	if l.threshold > 0 {
+		l.timer = time.AfterFunc(l.threshold, func() {
+			l.reporter(*o, time.Since(o.Since))
+		})
+	}
+}
+
+// released 释放锁之前清除持有锁的goroutine，调用时需要持有l.mu
+func (l *ChanLock) released() {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+
+	l.owner = nil
+}
+
+// goroutineID 从调用栈的第一行解析goroutine id，比如：goroutine 18 [running]:
+func goroutineID(stack []byte) int64 {
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i > 0 {
+		stack = stack[:i]
+	}
+
+	id, _ := strconv.ParseInt(string(stack), 10, 64)
+	return id
+}
+
+// defaultReporter 默认打印长时间持有锁的goroutine
+func defaultReporter(o Owner, held time.Duration) {
+	log.Printf("chanlock held by goroutine %d for %v\nstack:%s\n", o.Goroutine, held, o.Stack)
 }
@@ -1,6 +1,7 @@
 package chanlock
 
 import (
+	"context"
 	"log"
 	"sync"
 	"testing"
@@ -72,6 +73,107 @@ func TestTryLock(t *testing.T) {
 	log.Println("count: ", count)
 }
 
+// waiting 返回等待加锁的goroutine个数
+func (l *ChanLock) waiting() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.waiters.Len()
+}
+
+func TestLockFIFO(t *testing.T) {
+	chLock := NewChanLock()
+	chLock.Lock()
+
+	var (
+		wg    sync.WaitGroup
+		order []int
+	)
+
+	nums := 10
+	wg.Add(nums)
+	for i := 0; i < nums; i++ {
+		index := i
+		go func() {
+			defer wg.Done()
+
+			chLock.Lock()
+			defer chLock.Unlock()
+			order = append(order, index)
+		}()
+
+		// 等待当前goroutine开始排队，保证排队的顺序
+		for chLock.waiting() != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	chLock.Unlock()
+	wg.Wait()
+
+	log.Println("lock order: ", order)
+	for i, index := range order {
+		if i != index {
+			t.Fatalf("the waiters should acquire the lock in FIFO order: %v", order)
+		}
+	}
+}
+
+func TestLockContext(t *testing.T) {
+	chLock := NewChanLock()
+	chLock.Lock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := chLock.LockContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("lock should timeout,err: %v", err)
+	}
+
+	if n := chLock.waiting(); n != 0 {
+		t.Fatalf("the canceled waiter should be removed,waiting: %d", n)
+	}
+
+	if chLock.TryLock() {
+		t.Fatal("try lock should fail when the lock is held")
+	}
+
+	chLock.Unlock()
+	if err := chLock.LockContext(context.Background()); err != nil {
+		t.Fatalf("lock error: %v", err)
+	}
+
+	chLock.Unlock()
+}
+
+func TestLockDebug(t *testing.T) {
+	reported := make(chan Owner, 1)
+	chLock := NewChanLock(WithDebug(10*time.Millisecond), WithReporter(func(o Owner, held time.Duration) {
+		log.Printf("lock held by goroutine %d for %v\nstack:%s\n", o.Goroutine, held, o.Stack)
+		reported <- o
+	}))
+
+	chLock.Lock()
+	owner := chLock.Owner()
+	if owner == nil || owner.Goroutine == 0 || len(owner.Stack) == 0 {
+		t.Fatalf("the lock owner should be recorded: %+v", owner)
+	}
+
+	select {
+	case o := <-reported:
+		if o.Goroutine != owner.Goroutine {
+			t.Fatalf("invalid reported owner: %d", o.Goroutine)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the long hold should be reported")
+	}
+
+	chLock.Unlock()
+	if chLock.Owner() != nil {
+		t.Fatal("the lock owner should be cleared after unlock")
+	}
+}
+
 /**$ go test -v -test.run TestChanLock
 2019/11/27 21:59:50 current count:  1000
 2019/11/27 21:59:50 count:  1001
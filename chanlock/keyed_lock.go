@@ -0,0 +1,103 @@
+package chanlock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// KeyedLock 按照key加锁，每个key按需创建一个ChanLock
+// 没有goroutine持有或者等待某个key的锁时，该key的锁会被回收
+type KeyedLock struct {
+	mu    sync.Mutex
+	opts  []Option
+	locks map[string]*keyedEntry
+}
+
+// keyedEntry 引用计数的ChanLock
+type keyedEntry struct {
+	lock *ChanLock
+	refs int // 持有或者等待该锁的goroutine个数
+}
+
+// NewKeyedLock 创建按照key加锁的实例，opts用于创建每个key的ChanLock
+func NewKeyedLock(opts ...Option) *KeyedLock {
+	return &KeyedLock{
+		opts:  opts,
+		locks: make(map[string]*keyedEntry),
+	}
+}
+
+// Lock 对key加锁，如果key的锁已经被持有，该方法就会阻塞，直到锁释放为止
+func (k *KeyedLock) Lock(key string) {
+	k.acquire(key).lock.Lock()
+}
+
+// LockContext 对key加锁，直到获得锁或者ctx取消为止
+func (k *KeyedLock) LockContext(ctx context.Context, key string) error {
+	e := k.acquire(key)
+	if err := e.lock.LockContext(ctx); err != nil {
+		k.release(key, e)
+		return err
+	}
+
+	return nil
+}
+
+// TryLock 在timeout时间内尝试对key加锁，默认DefaultLockTimeout
+func (k *KeyedLock) TryLock(key string, timeout ...time.Duration) bool {
+	e := k.acquire(key)
+	if e.lock.TryLock(timeout...) {
+		return true
+	}
+
+	k.release(key, e)
+	return false
+}
+
+// Unlock 对key解锁
+func (k *KeyedLock) Unlock(key string) {
+	k.mu.Lock()
+	e, ok := k.locks[key]
+	k.mu.Unlock()
+	if !ok {
+		panic("chanlock: unlock of unlocked key " + key)
+	}
+
+	e.lock.Unlock()
+	k.release(key, e)
+}
+
+// Len 返回当前还没有被回收的key的个数
+func (k *KeyedLock) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return len(k.locks)
+}
+
+// acquire 获取key的锁并增加引用计数，不存在时创建
+func (k *KeyedLock) acquire(key string) *keyedEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	e, ok := k.locks[key]
+	if !ok {
+		e = &keyedEntry{lock: NewChanLock(k.opts...)}
+		k.locks[key] = e
+	}
+
+	e.refs++
+	return e
+}
+
+// release 减少引用计数，没有引用时回收key的锁
+func (k *KeyedLock) release(key string, e *keyedEntry) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	e.refs--
+	if e.refs == 0 {
+		delete(k.locks, key)
+	}
+}
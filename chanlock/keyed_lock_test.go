@@ -0,0 +1,68 @@
+package chanlock
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyedLock(t *testing.T) {
+	l := NewKeyedLock()
+	l.Lock("a")
+	if l.TryLock("a") {
+		t.Fatal("try lock should fail when the key is locked")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.LockContext(ctx, "a"); err != context.DeadlineExceeded {
+		t.Fatalf("lock should timeout,err: %v", err)
+	}
+
+	if !l.TryLock("b") {
+		t.Fatal("the different keys should not block each other")
+	}
+
+	l.Unlock("a")
+	l.Unlock("b")
+	if n := l.Len(); n != 0 {
+		t.Fatalf("the idle keys should be removed,len: %d", n)
+	}
+}
+
+func TestKeyedLockRace(t *testing.T) {
+	var (
+		l      = NewKeyedLock()
+		counts = make([]int, 10)
+		wg     sync.WaitGroup
+	)
+
+	nums := 1000
+	wg.Add(nums)
+	for i := 0; i < nums; i++ {
+		go func(i int) {
+			defer wg.Done()
+
+			key := strconv.Itoa(i % 10)
+			l.Lock(key)
+			defer l.Unlock(key)
+			counts[i%10]++
+		}(i)
+	}
+
+	wg.Wait()
+	log.Println("counts: ", counts)
+
+	for i, n := range counts {
+		if n != 100 {
+			t.Fatalf("key %d count should be 100,got: %d", i, n)
+		}
+	}
+
+	if n := l.Len(); n != 0 {
+		t.Fatalf("the idle keys should be removed,len: %d", n)
+	}
+}
@@ -0,0 +1,172 @@
+package chanlock
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// RWChanLock 读写锁，多个读者可以同时持有读锁，写锁是排他的
+// 等待者按照先来先服务(FIFO)的顺序获得锁，排在写者后面的读者需要等写者释放锁，避免写者饿死
+type RWChanLock struct {
+	mu      sync.Mutex
+	readers int       // 持有读锁的goroutine个数
+	writer  bool      // 是否有goroutine持有写锁
+	waiters list.List // 等待加锁的goroutine，元素为*rwWaiter
+}
+
+// rwWaiter 等待加锁的goroutine，获得锁时关闭ch
+type rwWaiter struct {
+	ch    chan struct{}
+	write bool
+}
+
+// NewRWChanLock 实例化一个读写锁对象
+func NewRWChanLock() *RWChanLock {
+	return &RWChanLock{}
+}
+
+// Lock 加写锁，直到获得锁为止
+func (l *RWChanLock) Lock() {
+	_ = l.LockContext(context.Background())
+}
+
+// LockContext 加写锁，直到获得锁或者ctx取消为止
+// 获得锁返回nil，ctx取消返回ctx.Err()，此时没有持有锁
+func (l *RWChanLock) LockContext(ctx context.Context) error {
+	return l.lockContext(ctx, true)
+}
+
+// Unlock 解写锁，按照先来先服务的顺序把锁交给等待者
+func (l *RWChanLock) Unlock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.writer {
+		panic("chanlock: unlock of unlocked rw lock")
+	}
+
+	l.writer = false
+	l.grant()
+}
+
+// RLock 加读锁，直到获得锁为止
+func (l *RWChanLock) RLock() {
+	_ = l.RLockContext(context.Background())
+}
+
+// RLockContext 加读锁，直到获得锁或者ctx取消为止
+// 获得锁返回nil，ctx取消返回ctx.Err()，此时没有持有锁
+func (l *RWChanLock) RLockContext(ctx context.Context) error {
+	return l.lockContext(ctx, false)
+}
+
+// RUnlock 解读锁，最后一个读者释放锁时把锁交给等待的写者
+func (l *RWChanLock) RUnlock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.readers == 0 {
+		panic("chanlock: runlock of unlocked rw lock")
+	}
+
+	l.readers--
+	l.grant()
+}
+
+// TryLock 在timeout时间内尝试加写锁，默认DefaultLockTimeout
+func (l *RWChanLock) TryLock(timeout ...time.Duration) bool {
+	return l.tryLockTimeout(true, timeout...)
+}
+
+// TryRLock 在timeout时间内尝试加读锁，默认DefaultLockTimeout
+func (l *RWChanLock) TryRLock(timeout ...time.Duration) bool {
+	return l.tryLockTimeout(false, timeout...)
+}
+
+// tryLockTimeout 指定时间内的乐观锁
+func (l *RWChanLock) tryLockTimeout(write bool, timeout ...time.Duration) bool {
+	expire := DefaultLockTimeout
+	if len(timeout) > 0 && timeout[0] > 0 {
+		expire = timeout[0]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), expire)
+	defer cancel()
+
+	return l.lockContext(ctx, write) == nil
+}
+
+// lockContext 加读锁或者写锁，直到获得锁或者ctx取消为止
+func (l *RWChanLock) lockContext(ctx context.Context, write bool) error {
+	l.mu.Lock()
+	if l.waiters.Len() == 0 && l.available(write) {
+		l.take(write)
+		l.mu.Unlock()
+		return nil
+	}
+
+	// 排队等待，释放锁时按照先来先服务的顺序把锁交给等待者
+	w := &rwWaiter{ch: make(chan struct{}), write: write}
+	elem := l.waiters.PushBack(w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ch:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-w.ch:
+			// ctx取消的同时获得了锁，需要释放锁交给下一个等待者
+			l.mu.Unlock()
+			if write {
+				l.Unlock()
+			} else {
+				l.RUnlock()
+			}
+		default:
+			// 排在前面的写者离开后，后面的读者可能可以获得锁
+			l.waiters.Remove(elem)
+			l.grant()
+			l.mu.Unlock()
+		}
+
+		return ctx.Err()
+	}
+}
+
+// available 判断当前是否可以加锁，调用时需要持有l.mu
+func (l *RWChanLock) available(write bool) bool {
+	if write {
+		return !l.writer && l.readers == 0
+	}
+
+	return !l.writer
+}
+
+// take 持有读锁或者写锁，调用时需要持有l.mu
+func (l *RWChanLock) take(write bool) {
+	if write {
+		l.writer = true
+		return
+	}
+
+	l.readers++
+}
+
+// grant 按照先来先服务的顺序把锁交给等待者，连续排队的读者同时获得读锁
+// 调用时需要持有l.mu
+func (l *RWChanLock) grant() {
+	for front := l.waiters.Front(); front != nil; front = l.waiters.Front() {
+		w := front.Value.(*rwWaiter)
+		if !l.available(w.write) {
+			return
+		}
+
+		l.take(w.write)
+		l.waiters.Remove(front)
+		close(w.ch)
+	}
+}
@@ -0,0 +1,163 @@
+package chanlock
+
+import (
+	"context"
+	"log"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRWTryLock(t *testing.T) {
+	l := NewRWChanLock()
+	if !l.TryRLock() || !l.TryRLock() {
+		t.Fatal("the read lock should be shared")
+	}
+
+	if l.TryLock() {
+		t.Fatal("try lock should fail when the read lock is held")
+	}
+
+	l.RUnlock()
+	l.RUnlock()
+
+	if !l.TryLock() {
+		t.Fatal("try lock should success after read unlock")
+	}
+
+	if l.TryRLock() {
+		t.Fatal("try read lock should fail when the write lock is held")
+	}
+
+	l.Unlock()
+}
+
+func TestRWLockFIFO(t *testing.T) {
+	l := NewRWChanLock()
+	l.RLock()
+
+	// 写者排队后，后来的读者需要等写者释放锁，避免写者饿死
+	locked := make(chan struct{})
+	go func() {
+		l.Lock()
+		close(locked)
+	}()
+
+	for l.waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if l.TryRLock(5 * time.Millisecond) {
+		t.Fatal("the reader should wait for the queued writer")
+	}
+
+	// 取消等待的读者离开队列，不影响写者
+	if l.waiting() != 1 {
+		t.Fatalf("the canceled reader should leave the queue,waiting:%d", l.waiting())
+	}
+
+	l.RUnlock()
+	<-locked
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.RLockContext(ctx)
+	}()
+
+	l.Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("the reader should get the lock after the writer unlock,err:%v", err)
+	}
+
+	l.RUnlock()
+}
+
+func TestRWLockCancel(t *testing.T) {
+	l := NewRWChanLock()
+	l.RLock()
+
+	// 排在最前面的写者取消后，后面的读者可以立即获得读锁
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- l.LockContext(ctx)
+	}()
+
+	for l.waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	read := make(chan struct{})
+	go func() {
+		l.RLock()
+		close(read)
+	}()
+
+	for l.waiting() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("the writer should be canceled,err:%v", err)
+	}
+
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("the reader should get the lock after the writer canceled")
+	}
+
+	l.RUnlock()
+	l.RUnlock()
+}
+
+func TestRWChanLockRace(t *testing.T) {
+	var (
+		l     = NewRWChanLock()
+		cache = make(map[int]int)
+		wg    sync.WaitGroup
+	)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+
+			if !l.TryLock(time.Second) {
+				return
+			}
+
+			cache[i%10]++
+			l.Unlock()
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if l.TryRLock() {
+				_ = cache[i%10]
+				l.RUnlock()
+				return
+			}
+
+			l.RLock()
+			_ = cache[i%10]
+			l.RUnlock()
+		}(i)
+	}
+
+	wg.Wait()
+	log.Println("cache: ", cache)
+}
+
+// waiting 返回等待加锁的goroutine个数
+func (l *RWChanLock) waiting() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.waiters.Len()
+}
@@ -0,0 +1,118 @@
+package mutexlock
+
+import (
+	"sync"
+)
+
+// KeyedLock 按照key加锁，每个key按需创建一个读写锁
+// 没有goroutine持有或者等待某个key的锁时，该key的锁会被回收
+type KeyedLock struct {
+	mu    sync.Mutex
+	locks map[string]*keyedEntry
+}
+
+// keyedEntry 引用计数的读写锁
+type keyedEntry struct {
+	in   sync.RWMutex
+	refs int // 持有或者等待该锁的goroutine个数
+}
+
+// NewKeyedLock 创建按照key加锁的实例
+func NewKeyedLock() *KeyedLock {
+	return &KeyedLock{
+		locks: make(map[string]*keyedEntry),
+	}
+}
+
+// Lock 对key加写锁
+func (k *KeyedLock) Lock(key string) {
+	k.acquire(key).in.Lock()
+}
+
+// Unlock 对key解写锁
+func (k *KeyedLock) Unlock(key string) {
+	e := k.entry(key)
+	e.in.Unlock()
+	k.release(key, e)
+}
+
+// RLock 对key加读锁
+func (k *KeyedLock) RLock(key string) {
+	k.acquire(key).in.RLock()
+}
+
+// RUnlock 对key解读锁
+func (k *KeyedLock) RUnlock(key string) {
+	e := k.entry(key)
+	e.in.RUnlock()
+	k.release(key, e)
+}
+
+// TryLock 尝试对key加写锁
+func (k *KeyedLock) TryLock(key string) bool {
+	e := k.acquire(key)
+	if e.in.TryLock() {
+		return true
+	}
+
+	k.release(key, e)
+	return false
+}
+
+// TryRLock 尝试对key加读锁
+func (k *KeyedLock) TryRLock(key string) bool {
+	e := k.acquire(key)
+	if e.in.TryRLock() {
+		return true
+	}
+
+	k.release(key, e)
+	return false
+}
+
+// Len 返回当前还没有被回收的key的个数
+func (k *KeyedLock) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return len(k.locks)
+}
+
+// acquire 获取key的锁并增加引用计数，不存在时创建
+func (k *KeyedLock) acquire(key string) *keyedEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	e, ok := k.locks[key]
+	if !ok {
+		e = &keyedEntry{}
+		k.locks[key] = e
+	}
+
+	e.refs++
+	return e
+}
+
+// entry 获取解锁的key对应的锁
+func (k *KeyedLock) entry(key string) *keyedEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	e, ok := k.locks[key]
+	if !ok {
+		panic("mutexlock: unlock of unlocked key " + key)
+	}
+
+	return e
+}
+
+// release 减少引用计数，没有引用时回收key的锁
+func (k *KeyedLock) release(key string, e *keyedEntry) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	e.refs--
+	if e.refs == 0 {
+		delete(k.locks, key)
+	}
+}
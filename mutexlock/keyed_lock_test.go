@@ -0,0 +1,68 @@
+package mutexlock
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestKeyedLock(t *testing.T) {
+	l := NewKeyedLock()
+	l.Lock("a")
+	if l.TryLock("a") || l.TryRLock("a") {
+		t.Fatal("try lock should fail when the key is locked")
+	}
+
+	if !l.TryLock("b") {
+		t.Fatal("the different keys should not block each other")
+	}
+
+	l.Unlock("a")
+	l.Unlock("b")
+	if n := l.Len(); n != 0 {
+		t.Fatalf("the idle keys should be removed,len: %d", n)
+	}
+}
+
+func TestKeyedLockRace(t *testing.T) {
+	var (
+		l      = NewKeyedLock()
+		counts = make([]int, 10)
+		wg     sync.WaitGroup
+	)
+
+	for i := 0; i < 1000; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+
+			key := strconv.Itoa(i % 10)
+			l.Lock(key)
+			counts[i%10]++
+			l.Unlock(key)
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+
+			key := strconv.Itoa(i % 10)
+			l.RLock(key)
+			_ = counts[i%10]
+			l.RUnlock(key)
+		}(i)
+	}
+
+	wg.Wait()
+	log.Println("counts: ", counts)
+
+	for i, n := range counts {
+		if n != 100 {
+			t.Fatalf("key %d count should be 100,got: %d", i, n)
+		}
+	}
+
+	if n := l.Len(); n != 0 {
+		t.Fatalf("the idle keys should be removed,len: %d", n)
+	}
+}
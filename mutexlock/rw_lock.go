@@ -0,0 +1,45 @@
+package mutexlock
+
+import (
+	"sync"
+)
+
+// NewRWMutexLock 创建读写锁实例
+func NewRWMutexLock() *RWMutex {
+	return &RWMutex{}
+}
+
+// RWMutex 读写锁，在sync.RWMutex基础上提供TryLock和TryRLock
+type RWMutex struct {
+	in sync.RWMutex
+}
+
+// Lock 加写锁
+func (m *RWMutex) Lock() {
+	m.in.Lock()
+}
+
+// Unlock 解写锁
+func (m *RWMutex) Unlock() {
+	m.in.Unlock()
+}
+
+// RLock 加读锁
+func (m *RWMutex) RLock() {
+	m.in.RLock()
+}
+
+// RUnlock 解读锁
+func (m *RWMutex) RUnlock() {
+	m.in.RUnlock()
+}
+
+// TryLock 尝试加写锁
+func (m *RWMutex) TryLock() bool {
+	return m.in.TryLock()
+}
+
+// TryRLock 尝试加读锁
+func (m *RWMutex) TryRLock() bool {
+	return m.in.TryRLock()
+}
@@ -0,0 +1,67 @@
+package mutexlock
+
+import (
+	"log"
+	"sync"
+	"testing"
+)
+
+func TestRWTryLock(t *testing.T) {
+	mutex := NewRWMutexLock()
+	if !mutex.TryRLock() || !mutex.TryRLock() {
+		t.Fatal("the read lock should be shared")
+	}
+
+	if mutex.TryLock() {
+		t.Fatal("try lock should fail when the read lock is held")
+	}
+
+	mutex.RUnlock()
+	mutex.RUnlock()
+
+	if !mutex.TryLock() {
+		t.Fatal("try lock should success after read unlock")
+	}
+
+	if mutex.TryRLock() {
+		t.Fatal("try read lock should fail when the write lock is held")
+	}
+
+	mutex.Unlock()
+}
+
+func TestRWRace(t *testing.T) {
+	var (
+		mu    RWMutex
+		cache = make(map[int]int)
+		wg    sync.WaitGroup
+	)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+
+			mu.Lock()
+			cache[i%10]++
+			mu.Unlock()
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if mu.TryRLock() {
+				_ = cache[i%10]
+				mu.RUnlock()
+				return
+			}
+
+			mu.RLock()
+			_ = cache[i%10]
+			mu.RUnlock()
+		}(i)
+	}
+
+	wg.Wait()
+	log.Println("cache: ", cache)
+}
@@ -0,0 +1,122 @@
+package mutexlock
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWeightExceeded 申请的权重超过了信号量的容量，永远无法获得
+var ErrWeightExceeded = errors.New("semaphore acquire weight exceeds size")
+
+// Semaphore 带权重的信号量，可用于限制访问mysql、下游http接口等资源的并发数
+// 等待者按照先来先服务(FIFO)的顺序获得信号量，大权重的等待者不会被小权重的等待者饿死
+type Semaphore struct {
+	size    int64
+	mu      sync.Mutex
+	cur     int64     // 已经被获取的权重
+	waiters list.List // 等待的goroutine，元素为*semaWaiter
+}
+
+// semaWaiter 等待获取信号量的goroutine
+type semaWaiter struct {
+	n     int64
+	ready chan struct{} // 获得信号量后关闭
+}
+
+// NewSemaphore 创建容量为n的信号量
+func NewSemaphore(n int64) *Semaphore {
+	return &Semaphore{
+		size: n,
+	}
+}
+
+// Acquire 获取权重为n的信号量，直到获得信号量或者ctx取消为止
+// 获得信号量返回nil，ctx取消返回ctx.Err()，此时没有获得信号量
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	if n > s.size {
+		s.mu.Unlock()
+		return ErrWeightExceeded
+	}
+
+	w := &semaWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// ctx取消的同时获得了信号量，需要释放掉
+			s.cur -= n
+			s.notifyWaiters()
+		default:
+			// 排在第一位的等待者取消后，后面的等待者可能已经可以获得信号量
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if front && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire 尝试获取权重为n的信号量，不阻塞，成功返回true
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ok := s.size-s.cur >= n && s.waiters.Len() == 0
+	if ok {
+		s.cur += n
+	}
+
+	return ok
+}
+
+// Release 释放权重为n的信号量
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cur -= n
+	if s.cur < 0 {
+		panic("mutexlock: semaphore released more than held")
+	}
+
+	s.notifyWaiters()
+}
+
+// notifyWaiters 按照顺序唤醒可以获得信号量的等待者，调用时需要持有s.mu
+func (s *Semaphore) notifyWaiters() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			break
+		}
+
+		w := front.Value.(*semaWaiter)
+		if s.size-s.cur < w.n {
+			// 剩余的权重不够第一个等待者，后面的等待者也不能获得，避免大权重的等待者饿死
+			break
+		}
+
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,171 @@
+package mutexlock
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	sem := NewSemaphore(3)
+	if !sem.TryAcquire(2) {
+		t.Fatal("try acquire should success")
+	}
+
+	if sem.TryAcquire(2) {
+		t.Fatal("try acquire should fail when the weight is not enough")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(ctx, 2); err != context.DeadlineExceeded {
+		t.Fatalf("acquire should timeout,err: %v", err)
+	}
+
+	if err := sem.Acquire(context.Background(), 4); err != ErrWeightExceeded {
+		t.Fatalf("acquire weight exceeds size should fail,err: %v", err)
+	}
+
+	sem.Release(2)
+	if err := sem.Acquire(context.Background(), 3); err != nil {
+		t.Fatalf("acquire error: %v", err)
+	}
+
+	sem.Release(3)
+}
+
+func TestSemaphoreFIFO(t *testing.T) {
+	sem := NewSemaphore(2)
+	sem.Acquire(context.Background(), 2)
+
+	// 大权重的等待者排在前面，后面小权重的等待者不能插队
+	acquired := make(chan int64, 2)
+	go func() {
+		sem.Acquire(context.Background(), 2)
+		acquired <- 2
+	}()
+
+	for waiting(sem) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	go func() {
+		sem.Acquire(context.Background(), 1)
+		acquired <- 1
+	}()
+
+	for waiting(sem) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if sem.TryAcquire(1) {
+		t.Fatal("try acquire should fail when there are waiters")
+	}
+
+	sem.Release(1)
+	select {
+	case n := <-acquired:
+		t.Fatalf("the waiter %d should not acquire before the first waiter", n)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	sem.Release(1)
+	if n := <-acquired; n != 2 {
+		t.Fatalf("the first waiter should acquire first,got: %d", n)
+	}
+
+	sem.Release(2)
+	if n := <-acquired; n != 1 {
+		t.Fatalf("the second waiter should acquire,got: %d", n)
+	}
+
+	sem.Release(1)
+}
+
+// TestSemaphoreCancel 第一个等待者取消后，后面的等待者可以获得信号量
+func TestSemaphoreCancel(t *testing.T) {
+	sem := NewSemaphore(2)
+	sem.Acquire(context.Background(), 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sem.Acquire(ctx, 2)
+	}()
+
+	for waiting(sem) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		sem.Acquire(context.Background(), 1)
+		close(done)
+	}()
+
+	for waiting(sem) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errCh; err != context.Canceled {
+		t.Fatalf("acquire should be canceled,err: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the next waiter should acquire after the first waiter canceled")
+	}
+}
+
+func TestSemaphoreRace(t *testing.T) {
+	var (
+		sem              = NewSemaphore(5)
+		running, maxRuns int32
+		wg               sync.WaitGroup
+	)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			n := int64(i%3 + 1)
+			if err := sem.Acquire(context.Background(), n); err != nil {
+				t.Errorf("acquire error: %v", err)
+				return
+			}
+
+			defer sem.Release(n)
+
+			cur := atomic.AddInt32(&running, int32(n))
+			defer atomic.AddInt32(&running, -int32(n))
+			for {
+				m := atomic.LoadInt32(&maxRuns)
+				if cur <= m || atomic.CompareAndSwapInt32(&maxRuns, m, cur) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+		}(i)
+	}
+
+	wg.Wait()
+	log.Println("max weight: ", maxRuns)
+	if maxRuns > 5 {
+		t.Fatalf("the acquired weight should not exceed size,max: %d", maxRuns)
+	}
+}
+
+// waiting 返回等待获取信号量的goroutine个数
+func waiting(s *Semaphore) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.waiters.Len()
+}
@@ -83,6 +83,30 @@ func TestLock(t *testing.T) {
 	log.Println("ok")
 }
 
+// TestRenew 测试锁续期操作
+func TestRenew(t *testing.T) {
+	client := getClient()
+	l, err := New(client, "daheige_renew", "hello,world", 2)
+	if err != nil {
+		t.Fatalf("create redis lock instance err:%v", err)
+	}
+
+	if ok, err := l.TryLock(); ok {
+		log.Println("lock success")
+		if err := l.Renew(); err != nil {
+			t.Fatalf("renew lock err:%v", err)
+		}
+
+		l.Unlock()
+		if err := l.Renew(); err != ErrRedisLockNotHeld {
+			t.Fatalf("renew unlocked lock should fail,err:%v", err)
+		}
+	} else {
+		log.Println("lock fail")
+		log.Println("err: ", err)
+	}
+}
+
 /**
 === RUN   TestLock
 2022/04/27 22:52:01 lock fail
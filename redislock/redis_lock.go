@@ -3,6 +3,7 @@ package redislock
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/go-redis/redis"
 )
@@ -19,6 +20,9 @@ var (
 
 	// ErrRedisLockKeyInvalid lock key invalid
 	ErrRedisLockKeyInvalid = errors.New("lock key is empty")
+
+	// ErrRedisLockNotHeld redis lock is not held by current val
+	ErrRedisLockNotHeld = errors.New("redis lock is not held")
 )
 
 // lock lock data
@@ -83,3 +87,32 @@ func (l *lock) TryLock() (bool, error) {
 
 	return reply == "OK", nil
 }
+
+// renewScript lua脚本续期一个key，只有value匹配时才设置过期时间
+// 避免锁已经过期被其他client获得后，续期了其他client的锁
+var renewScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("expire", KEYS[1], ARGV[2])
+else
+	return 0
+end`
+
+// Renew 续期锁的过期时间为expire，成功返回nil
+// 锁已经过期或者被其他client持有时返回ErrRedisLockNotHeld
+func (l *lock) Renew() error {
+	n, err := l.client.Eval(renewScript, []string{l.key}, l.val, l.expire).Int64()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrRedisLockNotHeld
+	}
+
+	return nil
+}
+
+// Expire 返回锁的过期时间
+func (l *lock) Expire() time.Duration {
+	return time.Duration(l.expire) * time.Second
+}
@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis"
+)
+
+// Checkpoint 断点存储接口，保存执行成功的任务名称
+// runner重启后，Load返回的任务不会重复执行，所有任务执行成功后调用Clear清除断点
+// 默认的任务名称为task-{index}，需要保证重启前后添加任务的顺序一致
+type Checkpoint interface {
+	Load() ([]string, error)
+	Save(name string) error
+	Clear() error
+}
+
+var (
+	_ Checkpoint = (*FileCheckpoint)(nil)
+	_ Checkpoint = (*RedisCheckpoint)(nil)
+)
+
+// WithCheckpoint 设置断点存储，runner从上一次执行成功的任务之后继续执行
+func WithCheckpoint(c Checkpoint) Option {
+	return func(r *Runner) {
+		r.checkpoint = c
+	}
+}
+
+// loadCheckpoint 加载已经执行成功的任务
+func (r *Runner) loadCheckpoint() (map[string]bool, error) {
+	if r.checkpoint == nil {
+		return nil, nil
+	}
+
+	names, err := r.checkpoint.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make(map[string]bool, len(names))
+	for _, name := range names {
+		restored[name] = true
+	}
+
+	return restored, nil
+}
+
+// save 保存执行成功的任务，保存失败只记录日志，重启后该任务会重新执行
+func (r *Runner) save(t *task) {
+	if r.checkpoint == nil {
+		return
+	}
+
+	if err := r.checkpoint.Save(t.name); err != nil {
+		r.logger.Println("save checkpoint error: ", err, "name: ", t.name)
+	}
+}
+
+// clearCheckpoint 所有任务执行成功后清除断点
+func (r *Runner) clearCheckpoint() {
+	if r.checkpoint == nil {
+		return
+	}
+
+	if err := r.checkpoint.Clear(); err != nil {
+		r.logger.Println("clear checkpoint error: ", err)
+	}
+}
+
+// FileCheckpoint 基于文件的断点存储，每行保存一个执行成功的任务名称
+type FileCheckpoint struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCheckpoint 创建基于文件的断点存储
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{
+		path: path,
+	}
+}
+
+// Load implements Checkpoint.
+func (c *FileCheckpoint) Load() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.Open(c.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names, scanner.Err()
+}
+
+// Save implements Checkpoint.
+func (c *FileCheckpoint) Save(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.WriteString(name + "\n"); err == nil {
+		err = f.Sync()
+	}
+
+	if e := f.Close(); err == nil {
+		err = e
+	}
+
+	return err
+}
+
+// Clear implements Checkpoint.
+func (c *FileCheckpoint) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// RedisCheckpoint 基于redis set的断点存储
+// client可以通过goredis.RedisClientConf GetClient创建
+type RedisCheckpoint struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisCheckpoint 创建基于redis的断点存储，执行成功的任务保存在key对应的set中
+func NewRedisCheckpoint(client *redis.Client, key string) *RedisCheckpoint {
+	return &RedisCheckpoint{
+		client: client,
+		key:    key,
+	}
+}
+
+// Load implements Checkpoint.
+func (c *RedisCheckpoint) Load() ([]string, error) {
+	return c.client.SMembers(c.key).Result()
+}
+
+// Save implements Checkpoint.
+func (c *RedisCheckpoint) Save(name string) error {
+	return c.client.SAdd(c.key, name).Err()
+}
+
+// Clear implements Checkpoint.
+func (c *RedisCheckpoint) Clear() error {
+	return c.client.Del(c.key).Err()
+}
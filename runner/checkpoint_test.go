@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunnerCheckpoint test resume from the last completed task
+func TestRunnerCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runner.checkpoint")
+	errB := errors.New("task b error")
+
+	var runs []string
+	newRunner := func(failB bool) *Runner {
+		p := New(WithCheckpoint(NewFileCheckpoint(path)))
+		p.AddNamed("a", func() error {
+			runs = append(runs, "a")
+			return nil
+		})
+		p.AddNamed("b", func() error {
+			runs = append(runs, "b")
+			if failB {
+				return errB
+			}
+
+			return nil
+		}, "a")
+		p.AddNamed("c", func() error {
+			runs = append(runs, "c")
+			return nil
+		}, "b")
+
+		return p
+	}
+
+	if err := newRunner(true).Start(); !errors.Is(err, errB) {
+		t.Fatalf("the first run should fail,err:%v", err)
+	}
+
+	names, err := NewFileCheckpoint(path).Load()
+	if err != nil || len(names) != 1 || names[0] != "a" {
+		t.Fatalf("the checkpoint should save task a,names:%v err:%v", names, err)
+	}
+
+	runs = nil
+	p := newRunner(false)
+	if err := p.Start(); err != nil {
+		t.Fatalf("the resumed run should succeed,err:%v", err)
+	}
+
+	if len(runs) != 2 || runs[0] != "b" || runs[1] != "c" {
+		t.Fatalf("the resumed run should skip task a,runs:%v", runs)
+	}
+
+	if status := p.GetTaskStatus(); status["a"] != StatusSucceeded {
+		t.Fatalf("the restored task should be succeeded: %v", status)
+	}
+
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("the checkpoint should be cleared after all tasks succeeded,err:%v", err)
+	}
+}
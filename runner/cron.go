@@ -0,0 +1,297 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron cron表达式格式错误
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// Schedule 解析后的cron表达式，每个字段用bit位表示允许的取值
+type Schedule struct {
+	second, minute, hour, dom, month, dow uint64
+
+	// dom或dow为*时，只需要匹配另外一个字段
+	// 两者都指定了取值时，匹配其中任意一个即可，与标准cron保持一致
+	domStar, dowStar bool
+}
+
+// cron表达式每个字段的取值范围
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondBounds = bounds{min: 0, max: 59}
+	minuteBounds = bounds{min: 0, max: 59}
+	hourBounds   = bounds{min: 0, max: 23}
+	domBounds    = bounds{min: 1, max: 31}
+	monthBounds  = bounds{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+
+	// dow 0和7都表示周日
+	dowBounds = bounds{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// 常用的cron表达式简写
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseCron 解析标准的cron表达式
+// 支持5个字段：分 时 日 月 周，或者6个字段：秒 分 时 日 月 周
+// 每个字段支持*、?、数值、范围a-b、步长*/n或a-b/n、逗号分隔的列表
+// 月和周支持英文缩写，比如JAN、MON，同时支持@daily、@hourly等简写
+func ParseCron(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: %q expected 5 or 6 fields", ErrInvalidCron, spec)
+	}
+
+	s := &Schedule{
+		domStar: isStar(fields[3]),
+		dowStar: isStar(fields[5]),
+	}
+
+	var err error
+	for i, f := range []struct {
+		field  *uint64
+		bounds bounds
+	}{
+		{&s.second, secondBounds},
+		{&s.minute, minuteBounds},
+		{&s.hour, hourBounds},
+		{&s.dom, domBounds},
+		{&s.month, monthBounds},
+		{&s.dow, dowBounds},
+	} {
+		if *f.field, err = parseField(fields[i], f.bounds); err != nil {
+			return nil, fmt.Errorf("%w: %q %v", ErrInvalidCron, spec, err)
+		}
+	}
+
+	// 7和0一样表示周日
+	if s.dow&(1<<7) > 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+// MustParseCron 解析cron表达式，格式错误时panic
+func MustParseCron(spec string) *Schedule {
+	s, err := ParseCron(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func isStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// parseField 解析逗号分隔的字段
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		n, err := parseRange(expr, b)
+		if err != nil {
+			return 0, err
+		}
+
+		bits |= n
+	}
+
+	return bits, nil
+}
+
+// parseRange 解析 *、a、a-b 以及带有/n步长的表达式
+func parseRange(expr string, b bounds) (uint64, error) {
+	var (
+		start, end, step uint = 0, 0, 1
+		err              error
+	)
+
+	rangeAndStep := strings.Split(expr, "/")
+	if len(rangeAndStep) > 2 {
+		return 0, fmt.Errorf("too many slashes: %q", expr)
+	}
+
+	lowAndHigh := strings.Split(rangeAndStep[0], "-")
+	switch {
+	case len(lowAndHigh) > 2:
+		return 0, fmt.Errorf("too many hyphens: %q", expr)
+	case isStar(lowAndHigh[0]) && len(lowAndHigh) == 1:
+		start, end = b.min, b.max
+	default:
+		if start, err = parseValue(lowAndHigh[0], b); err != nil {
+			return 0, err
+		}
+
+		end = start
+		if len(lowAndHigh) == 2 {
+			if end, err = parseValue(lowAndHigh[1], b); err != nil {
+				return 0, err
+			}
+		} else if len(rangeAndStep) == 2 {
+			// a/n 表示从a开始到最大值，步长为n
+			end = b.max
+		}
+	}
+
+	if len(rangeAndStep) == 2 {
+		if step, err = parseNumber(rangeAndStep[1]); err != nil {
+			return 0, err
+		}
+
+		if step == 0 {
+			return 0, fmt.Errorf("step should be positive: %q", expr)
+		}
+	}
+
+	if start < b.min || end > b.max || start > end {
+		return 0, fmt.Errorf("out of range [%d, %d]: %q", b.min, b.max, expr)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+
+	return bits, nil
+}
+
+// parseValue 解析数值或者英文缩写
+func parseValue(s string, b bounds) (uint, error) {
+	if n, ok := b.names[strings.ToLower(s)]; ok {
+		return n, nil
+	}
+
+	return parseNumber(s)
+}
+
+func parseNumber(s string) (uint, error) {
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number: %q", s)
+	}
+
+	return uint(n), nil
+}
+
+// Next 返回t之后(不包括t)下一次触发的时间，精确到秒
+// 5年内都没有匹配的时间时(比如2月30日)，返回零值
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+
+	// 从下一秒开始匹配
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+	// 某个字段进位时，低位字段需要从最小值开始重新匹配
+	added := false
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for 1<<uint(t.Month())&s.month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.hour == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.minute == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for 1<<uint(t.Second())&s.second == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+// dayMatches 判断日期是否匹配dom和dow
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := 1<<uint(t.Day())&s.dom > 0
+	dowMatch := 1<<uint(t.Weekday())&s.dow > 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
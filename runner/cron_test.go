@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestParseCron test cron expression next run time
+func TestParseCron(t *testing.T) {
+	from := time.Date(2020, 5, 23, 20, 30, 5, 0, time.Local)
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2020, 5, 23, 20, 31, 0, 0, time.Local)},
+		{"* * * * * *", time.Date(2020, 5, 23, 20, 30, 6, 0, time.Local)},
+		{"*/15 * * * * *", time.Date(2020, 5, 23, 20, 30, 15, 0, time.Local)},
+		{"0 */2 * * *", time.Date(2020, 5, 23, 22, 0, 0, 0, time.Local)},
+		{"30 9-17 * * MON-FRI", time.Date(2020, 5, 25, 9, 30, 0, 0, time.Local)},
+		{"0 0 1,15 * *", time.Date(2020, 6, 1, 0, 0, 0, 0, time.Local)},
+		{"0 0 * FEB *", time.Date(2021, 2, 1, 0, 0, 0, 0, time.Local)},
+		{"0 12 * * 7", time.Date(2020, 5, 24, 12, 0, 0, 0, time.Local)},
+		{"0 0 13 * FRI", time.Date(2020, 5, 29, 0, 0, 0, 0, time.Local)},
+		{"0 0 29 2 ?", time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local)},
+		{"@hourly", time.Date(2020, 5, 23, 21, 0, 0, 0, time.Local)},
+		{"@daily", time.Date(2020, 5, 24, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("parse %q error: %v", tt.spec, err)
+		}
+
+		if next := s.Next(from); !next.Equal(tt.next) {
+			t.Fatalf("%q next run time should be %v,got: %v", tt.spec, tt.next, next)
+		}
+	}
+
+	if next := MustParseCron("0 0 30 2 *").Next(from); !next.IsZero() {
+		t.Fatalf("feb 30 should never run,got: %v", next)
+	}
+}
+
+// TestParseCronInvalid test invalid cron expression
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-2-3 * * * *",
+		"1/2/3 * * * *",
+	} {
+		if _, err := ParseCron(spec); !errors.Is(err, ErrInvalidCron) {
+			t.Fatalf("%q should be invalid,err: %v", spec, err)
+		}
+	}
+}
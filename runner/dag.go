@@ -0,0 +1,196 @@
+package runner
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrCycle 任务依赖存在环
+	ErrCycle = errors.New("task dependency cycle detected")
+
+	// ErrDuplicateTask 任务名称重复
+	ErrDuplicateTask = errors.New("duplicate task name")
+
+	// ErrUnknownDependency 依赖的任务不存在
+	ErrUnknownDependency = errors.New("unknown task dependency")
+)
+
+// Status 任务执行状态
+type Status int
+
+const (
+	// StatusPending 等待执行
+	StatusPending Status = iota
+
+	// StatusRunning 正在执行
+	StatusRunning
+
+	// StatusSucceeded 执行成功
+	StatusSucceeded
+
+	// StatusFailed 执行失败
+	StatusFailed
+
+	// StatusSkipped 依赖的任务失败或runner被终止，跳过执行
+	StatusSkipped
+)
+
+// String 返回任务状态的名称
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusRunning:
+		return "running"
+	case StatusSucceeded:
+		return "succeeded"
+	case StatusFailed:
+		return "failed"
+	case StatusSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("status(%d)", int(s))
+	}
+}
+
+// task 任务节点
+type task struct {
+	index     int                             // 任务添加的顺序
+	name      string                          // 任务名称
+	fn        func(ctx context.Context) error // 执行的任务func
+	dependsOn []string                        // 依赖的任务名称
+}
+
+// graph 任务依赖关系图
+type graph struct {
+	children [][]int // 每个任务的后继任务
+	indegree []int   // 每个任务未完成的依赖数
+}
+
+// buildGraph 构建任务依赖关系图，并检测任务名称重复、依赖不存在以及依赖环
+func buildGraph(tasks []*task) (*graph, error) {
+	names := make(map[string]int, len(tasks))
+	for _, t := range tasks {
+		if _, ok := names[t.name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateTask, t.name)
+		}
+
+		names[t.name] = t.index
+	}
+
+	g := &graph{
+		children: make([][]int, len(tasks)),
+		indegree: make([]int, len(tasks)),
+	}
+
+	for _, t := range tasks {
+		for _, dep := range t.dependsOn {
+			parent, ok := names[dep]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, t.name, dep)
+			}
+
+			g.children[parent] = append(g.children[parent], t.index)
+			g.indegree[t.index]++
+		}
+	}
+
+	if cycle := g.findCycle(); len(cycle) > 0 {
+		path := make([]string, 0, len(cycle))
+		for _, k := range cycle {
+			path = append(path, tasks[k].name)
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrCycle, strings.Join(path, " -> "))
+	}
+
+	return g, nil
+}
+
+// findCycle 深度优先遍历查找依赖环，返回环上的任务
+func (g *graph) findCycle() []int {
+	const (
+		white = iota // 未访问
+		gray         // 访问中
+		black        // 访问完毕
+	)
+
+	color := make([]int, len(g.children))
+	var stack []int
+
+	var visit func(k int) []int
+	visit = func(k int) []int {
+		color[k] = gray
+		stack = append(stack, k)
+		for _, c := range g.children[k] {
+			switch color[c] {
+			case gray:
+				// 从栈中找到环的起点
+				for i := range stack {
+					if stack[i] == c {
+						cycle := append([]int{}, stack[i:]...)
+						return append(cycle, c)
+					}
+				}
+			case white:
+				if cycle := visit(c); len(cycle) > 0 {
+					return cycle
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		color[k] = black
+		return nil
+	}
+
+	for k := range g.children {
+		if color[k] == white {
+			if cycle := visit(k); len(cycle) > 0 {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// readyQueue 可执行的任务队列，按照任务添加的顺序执行，实现了heap.Interface
+type readyQueue []int
+
+// Len implements heap.Interface.
+func (q readyQueue) Len() int { return len(q) }
+
+// Less implements heap.Interface.
+func (q readyQueue) Less(i, j int) bool { return q[i] < q[j] }
+
+// Swap implements heap.Interface.
+func (q readyQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+// Push implements heap.Interface.
+func (q *readyQueue) Push(x interface{}) {
+	*q = append(*q, x.(int))
+}
+
+// Pop implements heap.Interface.
+func (q *readyQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	k := old[n-1]
+	*q = old[:n-1]
+	return k
+}
+
+// push 添加可执行的任务
+func (q *readyQueue) push(k int) {
+	heap.Push(q, k)
+}
+
+// pop 取出最先添加的任务
+func (q *readyQueue) pop() int {
+	return heap.Pop(q).(int)
+}
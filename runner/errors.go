@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorPolicy 任务执行出错后的处理策略
+type ErrorPolicy int
+
+const (
+	// ContinueOnError 任务出错后继续执行其他任务，只跳过依赖出错任务的后继任务，默认策略
+	ContinueOnError ErrorPolicy = iota
+
+	// FailFast 任务出错后不再执行新的任务，并取消正在执行的任务
+	// 被取消的任务标记为StatusSkipped，不计入返回的TaskErrors
+	FailFast
+)
+
+// WithErrorPolicy 设置任务执行出错后的处理策略，默认ContinueOnError
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(r *Runner) {
+		r.errorPolicy = policy
+	}
+}
+
+// TaskError 单个任务的执行错误
+type TaskError struct {
+	Index int    // 任务添加的顺序
+	Name  string // 任务名称
+	Err   error  // 任务返回的错误
+}
+
+// Error implements error interface.
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %s(%d): %v", e.Name, e.Index, e.Err)
+}
+
+// Unwrap 返回任务返回的错误
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// TaskErrors 所有任务的执行错误，按照任务添加的顺序排列
+// 支持errors.Is/errors.As判断其中任意一个任务的错误
+type TaskErrors []*TaskError
+
+// Error implements error interface.
+func (e TaskErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, te := range e {
+		msgs = append(msgs, te.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Is 判断是否有任务的错误匹配target
+func (e TaskErrors) Is(target error) bool {
+	for _, te := range e {
+		if errors.Is(te, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As 查找第一个匹配target的任务错误
+func (e TaskErrors) As(target interface{}) bool {
+	for _, te := range e {
+		if errors.As(te, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ByIndex 根据任务添加的顺序获取任务的错误
+func (e TaskErrors) ByIndex(index int) error {
+	for _, te := range e {
+		if te.Index == index {
+			return te.Err
+		}
+	}
+
+	return nil
+}
+
+// ByName 根据任务名称获取任务的错误
+func (e TaskErrors) ByName(name string) error {
+	for _, te := range e {
+		if te.Name == name {
+			return te.Err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"time"
+)
+
+// EventType 任务执行事件类型
+type EventType int
+
+const (
+	// EventBeforeTask 任务开始执行
+	EventBeforeTask EventType = iota
+
+	// EventAfterTask 任务执行完毕，包括执行成功和失败
+	EventAfterTask
+
+	// EventTimeout 任务执行超时或者runner执行超时
+	EventTimeout
+
+	// EventInterrupt runner接收到中断信号
+	EventInterrupt
+)
+
+// String returns event type name.
+func (e EventType) String() string {
+	switch e {
+	case EventBeforeTask:
+		return "before_task"
+	case EventAfterTask:
+		return "after_task"
+	case EventTimeout:
+		return "timeout"
+	case EventInterrupt:
+		return "interrupt"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 任务执行事件，可用于上报监控指标或者记录审计日志
+// runner级别的事件(runner超时、中断)Index为-1，Name为空，Start为runner开始执行的时间
+type Event struct {
+	Type     EventType     // 事件类型
+	Index    int           // 任务添加的顺序
+	Name     string        // 任务名称
+	Start    time.Time     // 任务开始执行的时间
+	Duration time.Duration // 任务执行耗时，EventBeforeTask为0
+	Err      error         // 任务返回的错误
+}
+
+// Hook 任务执行事件的回调函数
+// 任务事件的回调在runner的调度goroutine中依次执行，回调函数不应该阻塞
+type Hook func(e Event)
+
+// WithBeforeTask 添加任务开始执行的回调
+func WithBeforeTask(h Hook) Option {
+	return withHook(EventBeforeTask, h)
+}
+
+// WithAfterTask 添加任务执行完毕的回调
+func WithAfterTask(h Hook) Option {
+	return withHook(EventAfterTask, h)
+}
+
+// WithOnTimeout 添加任务超时或者runner超时的回调
+func WithOnTimeout(h Hook) Option {
+	return withHook(EventTimeout, h)
+}
+
+// WithOnInterrupt 添加runner接收到中断信号的回调
+func WithOnInterrupt(h Hook) Option {
+	return withHook(EventInterrupt, h)
+}
+
+func withHook(typ EventType, h Hook) Option {
+	return func(r *Runner) {
+		r.hooks[typ] = append(r.hooks[typ], h)
+	}
+}
+
+// emit 触发事件对应的回调
+func (r *Runner) emit(e Event) {
+	for _, h := range r.hooks[e.Type] {
+		h(e)
+	}
+}
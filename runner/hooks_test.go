@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"log"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestRunnerHooks test task lifecycle events
+func TestRunnerHooks(t *testing.T) {
+	errB := errors.New("task b error")
+
+	var before, after, timeouts []Event
+	p := New(
+		WithBeforeTask(func(e Event) {
+			before = append(before, e)
+		}),
+		WithAfterTask(func(e Event) {
+			log.Printf("task: %s duration: %v error: %v\n", e.Name, e.Duration, e.Err)
+			after = append(after, e)
+		}),
+		WithOnTimeout(func(e Event) {
+			timeouts = append(timeouts, e)
+		}),
+	)
+
+	p.AddNamed("a", func() error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	p.AddNamed("b", func() error {
+		return errB
+	})
+	p.AddNamedContext("c", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	p.SetTaskTimeout("c", 10*time.Millisecond)
+
+	p.Start()
+	if len(before) != 3 || len(after) != 3 {
+		t.Fatalf("every task should emit before and after events,before:%v after:%v", before, after)
+	}
+
+	if after[0].Name != "a" || after[0].Duration < 20*time.Millisecond || after[0].Err != nil {
+		t.Fatalf("invalid after event: %+v", after[0])
+	}
+
+	if after[1].Name != "b" || after[1].Err != errB {
+		t.Fatalf("invalid after event: %+v", after[1])
+	}
+
+	if len(timeouts) != 1 || timeouts[0].Name != "c" || !errors.Is(timeouts[0].Err, ErrTaskTimeout) {
+		t.Fatalf("task c should emit timeout event: %v", timeouts)
+	}
+}
+
+// TestRunnerTimeoutHook test runner timeout event
+func TestRunnerTimeoutHook(t *testing.T) {
+	events := make(chan Event, 1)
+	p := New(WithTimeout(10*time.Millisecond), WithOnTimeout(func(e Event) {
+		events <- e
+	}))
+	p.AddContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := p.Start(); err != ErrTimeout {
+		t.Fatalf("runner should timeout,err:%v", err)
+	}
+
+	e := <-events
+	if e.Type != EventTimeout || e.Index != -1 || e.Err != ErrTimeout {
+		t.Fatalf("invalid timeout event: %+v", e)
+	}
+}
+
+// TestRunnerInterruptHook test runner interrupt event
+func TestRunnerInterruptHook(t *testing.T) {
+	events := make(chan Event, 1)
+	p := New(WithOnInterrupt(func(e Event) {
+		events <- e
+	}))
+	p.AddContext(func(ctx context.Context) error {
+		p.interrupt <- syscall.SIGINT
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := p.Start(); err != ErrInterrupt {
+		t.Fatalf("runner should be interrupted,err:%v", err)
+	}
+
+	e := <-events
+	if e.Type != EventInterrupt || e.Err != ErrInterrupt {
+		t.Fatalf("invalid interrupt event: %+v", e)
+	}
+}
@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/daheige/tigago/redislock"
+)
+
+var (
+	// ErrLockHeld 其他实例正在执行runner，本次执行被跳过
+	ErrLockHeld = errors.New("runner lock is held by another instance")
+
+	// ErrRenewInterval 没有设置锁的续期间隔，并且无法根据锁的过期时间计算默认值
+	ErrRenewInterval = errors.New("runner lock renew interval should be positive")
+)
+
+// Locker 分布式锁接口，redislock.New返回的锁实现了该接口
+type Locker interface {
+	TryLock() (bool, error)
+	Unlock() error
+	Renew() error
+}
+
+// expirer 可以返回过期时间的锁，redislock.New返回的锁实现了该接口
+type expirer interface {
+	Expire() time.Duration
+}
+
+// WithLocker 设置分布式锁，多个实例部署时同一时刻只有一个实例执行runner
+// Start之前先加锁，加锁失败返回ErrLockHeld；执行过程中每隔renewInterval续期一次
+// 续期失败说明锁已经丢失，runner会取消正在执行的任务并返回续期的错误
+// 使用redislock时，每个实例的val需要不同，renewInterval应小于锁的过期时间，比如过期时间的1/3
+// renewInterval <= 0时，如果锁实现了Expire() time.Duration，默认为过期时间的1/3，否则Start返回ErrRenewInterval
+func WithLocker(l Locker, renewInterval time.Duration) Option {
+	return func(r *Runner) {
+		r.locker = l
+		r.renewInterval = renewInterval
+	}
+}
+
+// lock 加锁，返回释放锁的函数和锁丢失的通知通道
+func (r *Runner) lock() (func(), <-chan error, error) {
+	if r.locker == nil {
+		return func() {}, nil, nil
+	}
+
+	interval := r.renewInterval
+	if e, ok := r.locker.(expirer); ok && interval <= 0 {
+		interval = e.Expire() / 3
+	}
+
+	if interval <= 0 {
+		return nil, nil, ErrRenewInterval
+	}
+
+	ok, err := r.locker.TryLock()
+	if errors.Is(err, redislock.ErrRedisLockExists) || (err == nil && !ok) {
+		return nil, nil, ErrLockHeld
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		lost = make(chan error, 1)
+		quit = make(chan struct{})
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ticker.C:
+				if err := r.locker.Renew(); err != nil {
+					r.logger.Println("renew lock error: ", err)
+					lost <- err
+					return
+				}
+			}
+		}
+	}()
+
+	unlock := func() {
+		close(quit)
+		<-done
+
+		if err := r.locker.Unlock(); err != nil {
+			r.logger.Println("unlock error: ", err)
+		}
+	}
+
+	return unlock, lost, nil
+}
@@ -0,0 +1,191 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daheige/tigago/redislock"
+	"github.com/go-redis/redis"
+)
+
+// memLocker 内存实现的分布式锁，用于测试
+type memLocker struct {
+	mu       sync.Mutex
+	held     bool
+	renews   int
+	renewErr error
+}
+
+func (l *memLocker) TryLock() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held {
+		return false, redislock.ErrRedisLockExists
+	}
+
+	l.held = true
+	return true, nil
+}
+
+func (l *memLocker) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.held = false
+	return nil
+}
+
+func (l *memLocker) Renew() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.renews++
+	return l.renewErr
+}
+
+// TestRedisLocker test redislock implements Locker
+func TestRedisLocker(t *testing.T) {
+	l, err := redislock.New(redis.NewClient(&redis.Options{}), "runner", "host-1")
+	if err != nil {
+		t.Fatalf("create redis lock error: %v", err)
+	}
+
+	var _ Locker = l
+	var _ expirer = l
+}
+
+// TestRunnerLocker test only one instance runs at the same time
+func TestRunnerLocker(t *testing.T) {
+	locker := &memLocker{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	p := New(WithLocker(locker, 10*time.Millisecond))
+	p.Add(func() error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Start()
+	}()
+
+	<-started
+	other := New(WithLocker(locker, 10*time.Millisecond))
+	other.Add(func() error {
+		t.Error("the task should not run when the lock is held")
+		return nil
+	})
+
+	if err := other.Start(); err != ErrLockHeld {
+		t.Fatalf("the other instance should be skipped,err:%v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("runner exec error: %v", err)
+	}
+
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+	if locker.held || locker.renews == 0 {
+		t.Fatalf("the lock should be renewed and released,held:%v renews:%d", locker.held, locker.renews)
+	}
+}
+
+// TestRunnerLockLost test the runner stops when renew the lock failed
+func TestRunnerLockLost(t *testing.T) {
+	errLost := errors.New("lock lost")
+	locker := &memLocker{renewErr: errLost}
+
+	p := New(WithLocker(locker, 10*time.Millisecond))
+	p.AddContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := p.Start(); err != errLost {
+		t.Fatalf("the runner should stop after the lock lost,err:%v", err)
+	}
+}
+
+// loadCounter 记录Load调用次数的断点，用于测试
+type loadCounter struct {
+	loads int
+}
+
+func (c *loadCounter) Load() ([]string, error) {
+	c.loads++
+	return nil, nil
+}
+
+func (c *loadCounter) Save(name string) error { return nil }
+
+func (c *loadCounter) Clear() error { return nil }
+
+// TestRunnerLockBeforeCheckpoint test the checkpoint is loaded after the lock is acquired
+func TestRunnerLockBeforeCheckpoint(t *testing.T) {
+	locker := &memLocker{held: true}
+	cp := &loadCounter{}
+
+	p := New(WithLocker(locker, 10*time.Millisecond), WithCheckpoint(cp))
+	p.AddNamed("a", func() error {
+		return nil
+	})
+
+	if err := p.Start(); err != ErrLockHeld {
+		t.Fatalf("the runner should be skipped,err:%v", err)
+	}
+
+	if cp.loads != 0 {
+		t.Fatalf("the checkpoint should not be loaded without the lock,loads:%d", cp.loads)
+	}
+}
+
+// expireLocker 带有过期时间的内存锁，用于测试
+type expireLocker struct {
+	memLocker
+	expire time.Duration
+}
+
+func (l *expireLocker) Expire() time.Duration {
+	return l.expire
+}
+
+// TestRunnerRenewInterval test the default renew interval
+func TestRunnerRenewInterval(t *testing.T) {
+	// 没有续期间隔，也无法获取过期时间
+	p := New(WithLocker(&memLocker{}, 0))
+	p.Add(func() error {
+		return nil
+	})
+
+	if err := p.Start(); err != ErrRenewInterval {
+		t.Fatalf("the runner should reject the zero renew interval,err:%v", err)
+	}
+
+	// 默认按照过期时间的1/3续期
+	locker := &expireLocker{expire: 30 * time.Millisecond}
+	p = New(WithLocker(locker, 0))
+	p.Add(func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+
+	if err := p.Start(); err != nil {
+		t.Fatalf("runner exec error: %v", err)
+	}
+
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+	if locker.renews == 0 {
+		t.Fatal("the lock should be renewed by the default interval")
+	}
+}
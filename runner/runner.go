@@ -9,15 +9,22 @@
 // 此外这个执行者也是一个很不错的模式，比如我们写好之后，交给定时任务去执行即可
 // 比如cron，这个模式我们还可以扩展更高效率的并发，更多灵活的控制程序的生命周期
 // 更高效的监控等。
+// 支持通过AddNamed声明任务之间的依赖关系，runner会按照DAG有界并发执行任务。
+// 支持通过NewScheduler按照cron表达式周期性的执行runner。
+// 支持通过WithCheckpoint保存断点，重启后跳过已经执行成功的任务。
+// 支持通过WithLocker加分布式锁，多个实例部署时同一时刻只有一个实例执行。
 
 package runner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sort"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -28,8 +35,14 @@ var (
 
 	// ErrInterrupt interrupt signal
 	ErrInterrupt = errors.New("received interrupt signal")
+
+	// ErrTaskTimeout 单个任务执行超时
+	ErrTaskTimeout = errors.New("current task exec timeout")
 )
 
+// DefaultGracePeriod runner停止后默认等待正在执行的任务退出的时间
+var DefaultGracePeriod = 5 * time.Second
+
 // Logger log interface
 type Logger interface {
 	Println(msg ...interface{})
@@ -37,14 +50,25 @@ type Logger interface {
 
 // Runner 声明一个runner
 type Runner struct {
-	complete   chan error       // 有缓冲通道，存放所有任务运行后的结果状态
-	tasks      []func() error   // 执行的任务func,如果func没有错误返回，可以返回nil
-	timeout    time.Duration    // 所有的任务超时时间
-	timeCh     <-chan time.Time // 任务超时通道
-	logger     Logger           // 日志输出实例
-	interrupt  chan os.Signal   // 可以控制强制终止的信号
-	allErrors  map[int]error    // 发生错误的task index对应的错误
-	lastTaskId int              // 最后一次完成的任务id
+	tasks         []*task                  // 执行的任务,如果func没有错误返回，可以返回nil
+	parallelism   int                      // 任务最大并发执行数，默认1，即按照顺序执行
+	errorPolicy   ErrorPolicy              // 任务执行出错后的处理策略
+	checkpoint    Checkpoint               // 保存执行成功的任务，重启后从断点继续执行
+	locker        Locker                   // 分布式锁，保证同一时刻只有一个实例执行
+	renewInterval time.Duration            // 分布式锁续期的间隔
+	timeout       time.Duration            // 所有的任务超时时间
+	taskTimeout   time.Duration            // 每个任务默认的超时时间
+	timeouts      map[string]time.Duration // 指定任务的超时时间
+	timeCh        <-chan time.Time         // 任务超时通道
+	gracePeriod   time.Duration            // runner停止后等待正在执行的任务退出的时间
+	logger        Logger                   // 日志输出实例
+	interrupt     chan os.Signal           // 可以控制强制终止的信号
+	hooks         map[EventType][]Hook     // 任务执行事件的回调
+
+	mu         sync.Mutex        // 保护下面的任务执行结果
+	allErrors  map[int]error     // 发生错误的task index对应的错误
+	lastTaskId int               // 最后一次完成的任务id
+	status     map[string]Status // 每个任务的执行状态
 }
 
 // Option 采用func Option功能模式为Runner添加参数
@@ -54,8 +78,10 @@ type Option func(r *Runner)
 // 默认创建一个无超时任务的runner
 func New(opts ...Option) *Runner {
 	r := &Runner{
-		complete:  make(chan error, 1),
-		interrupt: make(chan os.Signal, 1), // 声明一个中断信号
+		interrupt:   make(chan os.Signal, 1), // 声明一个中断信号
+		parallelism: 1,
+		gracePeriod: DefaultGracePeriod,
+		hooks:       make(map[EventType][]Hook),
 	}
 
 	// 初始化option
@@ -67,6 +93,10 @@ func New(opts ...Option) *Runner {
 		r.logger = log.New(os.Stdout, "", log.LstdFlags)
 	}
 
+	if r.parallelism < 1 {
+		r.parallelism = 1
+	}
+
 	return r
 }
 
@@ -77,6 +107,14 @@ func WithTimeout(t time.Duration) Option {
 	}
 }
 
+// WithTaskTimeout 设置每个任务默认的超时时间
+// 任务超时后，传递给任务的ctx会被取消，任务返回ErrTaskTimeout
+func WithTaskTimeout(t time.Duration) Option {
+	return func(r *Runner) {
+		r.taskTimeout = t
+	}
+}
+
 // WithLogger 设置r.logger打印日志的句柄
 func WithLogger(l Logger) Option {
 	return func(r *Runner) {
@@ -84,107 +122,480 @@ func WithLogger(l Logger) Option {
 	}
 }
 
+// WithGracePeriod 设置runner超时、中断或者ctx取消后，等待正在执行的任务退出的时间
+// 默认DefaultGracePeriod，小于等于0时一直等待所有的任务退出
+func WithGracePeriod(d time.Duration) Option {
+	return func(r *Runner) {
+		r.gracePeriod = d
+	}
+}
+
+// WithParallelism 设置任务最大并发执行数，默认为1
+// 没有依赖关系的任务会并发执行
+func WithParallelism(n int) Option {
+	return func(r *Runner) {
+		r.parallelism = n
+	}
+}
+
 // Add 将需要执行的任务添加到r.tasks队列中
+// 任务名称为task-{index}，index为任务添加的顺序
 func (r *Runner) Add(tasks ...func() error) {
-	r.tasks = append(r.tasks, tasks...)
+	for _, fn := range tasks {
+		r.AddContext(withoutContext(fn))
+	}
+}
+
+// AddContext 添加可以感知ctx取消的任务
+// 当runner超时、接收到中断信号或者任务超时，ctx会被取消，任务应当尽快返回
+func (r *Runner) AddContext(tasks ...func(ctx context.Context) error) {
+	for _, fn := range tasks {
+		index := len(r.tasks)
+		r.tasks = append(r.tasks, &task{
+			index: index,
+			name:  fmt.Sprintf("task-%d", index),
+			fn:    fn,
+		})
+	}
+}
+
+// AddNamed 添加一个命名任务，dependsOn为依赖的任务名称
+// 只有依赖的任务全部执行成功后，才会执行该任务
+func (r *Runner) AddNamed(name string, fn func() error, dependsOn ...string) {
+	r.AddNamedContext(name, withoutContext(fn), dependsOn...)
+}
+
+// AddNamedContext 添加一个可以感知ctx取消的命名任务，dependsOn为依赖的任务名称
+func (r *Runner) AddNamedContext(name string, fn func(ctx context.Context) error, dependsOn ...string) {
+	r.tasks = append(r.tasks, &task{
+		index:     len(r.tasks),
+		name:      name,
+		fn:        fn,
+		dependsOn: dependsOn,
+	})
+}
+
+// SetTaskTimeout 设置指定名称任务的超时时间，它会覆盖WithTaskTimeout设置的默认超时时间
+func (r *Runner) SetTaskTimeout(name string, t time.Duration) {
+	if r.timeouts == nil {
+		r.timeouts = make(map[string]time.Duration)
+	}
+
+	r.timeouts[name] = t
 }
 
-// run 运行一个个任务,如果出错就返回错误信息
-func (r *Runner) run() (err error) {
-	for k, task := range r.tasks {
-		r.lastTaskId = k
+// withoutContext 将不感知ctx的任务包装为ctx任务
+func withoutContext(fn func() error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return fn()
+	}
+}
+
+// result 任务执行结果
+// 任务超时或者ctx取消后，先通知执行结果(finished)，任务的goroutine退出后再通知exited
+// 任务在exited之前一直占用并发数，保证同时执行的任务数不超过r.parallelism
+type result struct {
+	index    int
+	err      error
+	start    time.Time
+	duration time.Duration
+	finished bool // 任务的执行结果已经确定
+	exited   bool // 任务的goroutine已经退出
+}
 
-		if r.isInterrupt() {
-			err = ErrInterrupt
-			return
+// run 按照依赖关系运行任务,如果出错就返回TaskErrors
+// 同时执行的任务数不超过r.parallelism，依赖失败的任务会被跳过
+// ctx取消后，不再执行新的任务，restored中的任务已经执行成功，不再重复执行
+func (r *Runner) run(ctx context.Context, g *graph, restored map[string]bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		ready    readyQueue
+		running  int
+		stopped  bool
+		failFast bool // 已经因为快速失败取消了正在执行的任务
+		errs     TaskErrors
+		skipped  = make([]bool, len(r.tasks))
+		results  = make(chan result, 2*len(r.tasks))
+	)
+
+	for k, n := range g.indegree {
+		if n == 0 {
+			ready.push(k)
 		}
+	}
+
+	for {
+		for !stopped && running < r.parallelism && len(ready) > 0 {
+			if ctx.Err() != nil {
+				stopped = true
+				break
+			}
 
-		r.logger.Println("current run task id: ", k)
+			k := ready.pop()
+			t := r.tasks[k]
+			if restored[t.name] {
+				r.logger.Println("task restored from checkpoint: ", k, "name: ", t.name)
+				r.setStatus(t, StatusSucceeded)
+				r.release(g, k, skipped, &ready)
+				continue
+			}
 
-		err = r.doTask(task)
-		if err != nil {
-			r.logger.Println("current task exec occur error: ", err)
-			r.allErrors[k] = err
+			r.setStatus(t, StatusRunning)
+			r.logger.Println("current run task id: ", k, "name: ", t.name)
+
+			start := time.Now()
+			r.emit(Event{Type: EventBeforeTask, Index: k, Name: t.name, Start: start})
+
+			running++
+			go func() {
+				exited, err := r.doTask(ctx, t)
+				res := result{index: t.index, err: err, start: start, duration: time.Since(start), finished: true}
+				if exited != nil {
+					// 任务没有响应ctx取消，等待任务的goroutine退出后再释放并发数
+					results <- res
+					<-exited
+					results <- result{index: t.index, exited: true}
+					return
+				}
+
+				res.exited = true
+				results <- res
+			}()
 		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		if res.exited {
+			running--
+		}
+
+		if !res.finished {
+			continue
+		}
+
+		t := r.tasks[res.index]
+		e := Event{
+			Type:     EventAfterTask,
+			Index:    t.index,
+			Name:     t.name,
+			Start:    res.start,
+			Duration: res.duration,
+			Err:      res.err,
+		}
+
+		if errors.Is(res.err, ErrTaskTimeout) {
+			timeout := e
+			timeout.Type = EventTimeout
+			r.emit(timeout)
+		}
+
+		r.emit(e)
+
+		// 快速失败取消的任务不是失败的原因，标记为跳过，不记录错误
+		if failFast && errors.Is(res.err, context.Canceled) {
+			r.logger.Println("current task canceled by fail fast: ", t.name)
+			r.setStatus(t, StatusSkipped)
+			r.skip(g, res.index, skipped)
+			continue
+		}
+
+		if res.err != nil {
+			r.logger.Println("current task exec occur error: ", res.err)
+			r.setError(t, res.err)
+			errs = append(errs, &TaskError{Index: t.index, Name: t.name, Err: res.err})
+
+			// 跳过依赖失败任务的所有后继任务
+			r.skip(g, res.index, skipped)
+
+			// 不再执行新的任务，并取消正在执行的任务
+			if r.errorPolicy == FailFast && !stopped {
+				stopped, failFast = true, true
+				cancel()
+			}
+
+			continue
+		}
+
+		r.setStatus(t, StatusSucceeded)
+		r.save(t)
+		r.release(g, res.index, skipped, &ready)
 	}
 
-	return
+	if stopped {
+		r.skipPending()
+	}
+
+	if len(errs) == 0 {
+		if stopped {
+			return ctx.Err()
+		}
+
+		// 所有任务执行成功，清除断点
+		r.clearCheckpoint()
+		return nil
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Index < errs[j].Index
+	})
+
+	return errs
+}
+
+// release 任务k执行成功，将依赖已经全部完成的后继任务加入就绪队列
+func (r *Runner) release(g *graph, k int, skipped []bool, ready *readyQueue) {
+	for _, c := range g.children[k] {
+		g.indegree[c]--
+		if g.indegree[c] == 0 && !skipped[c] {
+			ready.push(c)
+		}
+	}
+}
+
+// skipPending 将未执行的任务标记为跳过
+func (r *Runner) skipPending() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, s := range r.status {
+		if s == StatusPending {
+			r.status[name] = StatusSkipped
+		}
+	}
+}
+
+// skip 跳过任务k的所有后继任务
+func (r *Runner) skip(g *graph, k int, skipped []bool) {
+	for _, c := range g.children[k] {
+		if skipped[c] {
+			continue
+		}
+
+		skipped[c] = true
+		r.setStatus(r.tasks[c], StatusSkipped)
+		r.skip(g, c, skipped)
+	}
+}
+
+// setStatus 设置任务执行状态
+func (r *Runner) setStatus(t *task, s Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status[t.name] = s
+	if s == StatusRunning {
+		r.lastTaskId = t.index
+	}
+}
+
+// setError 记录任务执行错误
+func (r *Runner) setError(t *task, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status[t.name] = StatusFailed
+	r.allErrors[t.index] = err
 }
 
 // doTask 执行每个task，需要捕获每个任务是否出现了panic异常
 // 防止一些个别任务出现了panic,从而导致整个tasks执行全部退出
-func (r *Runner) doTask(task func() error) (err error) {
-	defer func() {
-		if e := recover(); e != nil {
-			r.logger.Println("current task throw panic: ", e)
-			err = fmt.Errorf("current task panic: %v", e)
-		}
+// 任务超时或者ctx取消后立即返回错误，exited在任务的goroutine退出后关闭
+// 任务正常返回时exited为nil
+func (r *Runner) doTask(ctx context.Context, t *task) (exited <-chan struct{}, err error) {
+	timeout := r.taskTimeout
+	if d, ok := r.timeouts[t.name]; ok {
+		timeout = d
+	}
+
+	var (
+		taskCtx context.Context
+		cancel  context.CancelFunc
+	)
+
+	if timeout > 0 {
+		taskCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		taskCtx, cancel = context.WithCancel(ctx)
+	}
+
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if e := recover(); e != nil {
+				r.logger.Println("current task throw panic: ", e)
+				errCh <- fmt.Errorf("current task panic: %v", e)
+			}
+		}()
+
+		errCh <- t.fn(taskCtx)
 	}()
 
-	err = task()
+	select {
+	case err = <-errCh:
+		<-done
+		return nil, err
+	case <-taskCtx.Done():
+		if ctx.Err() == nil {
+			return done, fmt.Errorf("%w: %s", ErrTaskTimeout, t.name)
+		}
 
-	return
+		return done, ctx.Err()
+	}
 }
 
 // GetAllErrors 获取已经完成任务的error
 func (r *Runner) GetAllErrors() map[int]error {
-	return r.allErrors
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := make(map[int]error, len(r.allErrors))
+	for k, err := range r.allErrors {
+		errs[k] = err
+	}
+
+	return errs
 }
 
 // GetLastTaskId 获取最后一次完成任务id
 func (r *Runner) GetLastTaskId() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	return r.lastTaskId
 }
 
+// GetTaskStatus 获取每个任务的执行状态，key为任务名称
+func (r *Runner) GetTaskStatus() map[string]Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := make(map[string]Status, len(r.status))
+	for name, s := range r.status {
+		status[name] = s
+	}
+
+	return status
+}
+
 // Start 开始执行所有的任务
+// 有任务执行失败时返回TaskErrors，可以通过errors.Is/errors.As判断每个任务的错误
 func (r *Runner) Start() error {
+	return r.StartContext(context.Background())
+}
+
+// StartContext 开始执行所有的任务，ctx取消后runner停止执行
+// runner超时、接收到中断信号或者ctx取消，都会取消传递给任务的ctx，让正在执行的任务退出
+func (r *Runner) StartContext(ctx context.Context) error {
+	// 执行之前检测任务依赖关系
+	g, err := buildGraph(r.tasks)
+	if err != nil {
+		r.logger.Println("invalid task dependency: ", err)
+		return err
+	}
+
+	// 加锁成功之后才加载断点和执行任务，避免读到其他实例正在写入的断点
+	unlock, lost, err := r.lock()
+	if err != nil {
+		r.logger.Println("acquire lock error: ", err)
+		return err
+	}
+
+	defer unlock()
+
+	// 加载断点，跳过上一次已经执行成功的任务
+	restored, err := r.loadCheckpoint()
+	if err != nil {
+		r.logger.Println("load checkpoint error: ", err)
+		return err
+	}
+
 	// 接收系统退出信号
 	signal.Notify(r.interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(r.interrupt)
 
+	r.mu.Lock()
 	r.allErrors = make(map[int]error, len(r.tasks)+1)
+	r.status = make(map[string]Status, len(r.tasks))
+	for _, t := range r.tasks {
+		r.status[t.name] = StatusPending
+	}
+	r.mu.Unlock()
 
+	start := time.Now()
 	if r.timeout > 0 {
 		r.timeCh = time.After(r.timeout)
 	}
 
-	// 执行完毕的信号量
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 执行完毕的信号量，complete存放所有任务运行后的结果状态
 	done := make(chan struct{}, 1)
+	complete := make(chan error, 1)
 
 	// 开启独立goroutine执行任务
 	go func() {
+		defer close(done)
+
 		defer func() {
 			if e := recover(); e != nil {
 				r.logger.Println("exec task panic: ", e)
+				complete <- fmt.Errorf("exec task panic: %v", e)
 			}
-
-			close(done)
 		}()
 
-		r.complete <- r.run()
+		complete <- r.run(ctx, g, restored)
 	}()
 
+	// stop 取消正在执行的任务，在r.gracePeriod内等待所有任务的goroutine退出
+	stop := func() {
+		cancel()
+
+		var grace <-chan time.Time
+		if r.gracePeriod > 0 {
+			timer := time.NewTimer(r.gracePeriod)
+			defer timer.Stop()
+			grace = timer.C
+		}
+
+		select {
+		case <-done:
+		case <-grace:
+			r.logger.Println("tasks still running after grace period: ", r.gracePeriod)
+		}
+	}
+
 	select {
 	case <-r.timeCh:
 		r.logger.Println(ErrTimeout)
+		stop()
+		r.emit(Event{Type: EventTimeout, Index: -1, Start: start, Duration: time.Since(start), Err: ErrTimeout})
 		return ErrTimeout
+	case sg := <-r.interrupt: // 是否接受到操作系统的中断信号
+		r.logger.Println("received signal: ", sg.String())
+		stop()
+		r.emit(Event{Type: EventInterrupt, Index: -1, Start: start, Duration: time.Since(start), Err: ErrInterrupt})
+		return ErrInterrupt
+	case err := <-lost: // 分布式锁丢失
+		r.logger.Println("lock lost: ", err)
+		stop()
+		return err
+	case <-ctx.Done():
+		r.logger.Println("context done: ", ctx.Err())
+		stop()
+		return ctx.Err()
 	case <-done:
-		err := <-r.complete
+		err := <-complete
 		r.logger.Println("task complete status: ", err)
 		return err
 	}
 }
-
-// isInterrupt 检查是否接受到操作系统的中断信号
-// 一旦r.interrupt中可以接收值，就会通知Go Runtime停止接收中断信号，然后返回true
-// 这里如果没有default的话，select是会阻塞的，直到r.interrupt可以接收值为止
-func (r *Runner) isInterrupt() bool {
-	select {
-	case sg := <-r.interrupt: // 是否接受到操作系统的中断信号
-		signal.Stop(r.interrupt)
-		r.logger.Println("received signal: ", sg.String())
-
-		return true
-	default:
-		return false
-	}
-}
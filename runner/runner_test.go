@@ -1,8 +1,11 @@
 package runner
 
 import (
+	"context"
+	"errors"
 	"log"
 	"os"
+	"sync"
 	"testing"
 	"time"
 )
@@ -42,6 +45,296 @@ func createTask(id int) func() error {
 	}
 }
 
+// TestRunnerDAG test runner with task dependencies
+func TestRunnerDAG(t *testing.T) {
+	std := log.New(os.Stdout, "[runner] ", log.LstdFlags)
+	p := New(WithParallelism(2), WithLogger(std))
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	step := func(name string) func() error {
+		return func() error {
+			time.Sleep(100 * time.Millisecond)
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	p.AddNamed("report", step("report"), "clean", "stat")
+	p.AddNamed("clean", step("clean"))
+	p.AddNamed("stat", step("stat"))
+	p.AddNamed("notify", step("notify"), "report")
+
+	start := time.Now()
+	if err := p.Start(); err != nil {
+		t.Fatalf("runner start error:%v", err)
+	}
+
+	// clean and stat run in parallel,it costs about 300ms.
+	if d := time.Since(start); d >= 380*time.Millisecond {
+		t.Fatalf("the independent tasks should run in parallel,cost:%v", d)
+	}
+
+	if len(order) != 4 || order[2] != "report" || order[3] != "notify" {
+		t.Fatalf("invalid exec order: %v", order)
+	}
+
+	for name, s := range p.GetTaskStatus() {
+		if s != StatusSucceeded {
+			t.Fatalf("task %s status:%s", name, s)
+		}
+	}
+}
+
+// TestRunnerCycle test task dependency cycle detection
+func TestRunnerCycle(t *testing.T) {
+	p := New()
+	p.AddNamed("a", createTask(1), "c")
+	p.AddNamed("b", createTask(2), "a")
+	p.AddNamed("c", createTask(3), "b")
+
+	err := p.Start()
+	if !errors.Is(err, ErrCycle) {
+		t.Fatalf("the cycle should be detected,err:%v", err)
+	}
+
+	log.Println("error: ", err)
+
+	p = New()
+	p.AddNamed("a", createTask(1), "unknown")
+	if err = p.Start(); !errors.Is(err, ErrUnknownDependency) {
+		t.Fatalf("the unknown dependency should be detected,err:%v", err)
+	}
+}
+
+// TestRunnerSkip test the tasks depend on the failed task are skipped
+func TestRunnerSkip(t *testing.T) {
+	p := New(WithParallelism(3))
+	p.AddNamed("fetch", func() error {
+		return errors.New("mock fetch error")
+	})
+	p.AddNamed("parse", createTask(1), "fetch")
+	p.AddNamed("save", createTask(2), "parse")
+	p.AddNamed("other", createTask(3))
+
+	if err := p.Start(); err == nil {
+		t.Fatal("runner should return the task error")
+	}
+
+	expects := map[string]Status{
+		"fetch": StatusFailed,
+		"parse": StatusSkipped,
+		"save":  StatusSkipped,
+		"other": StatusSucceeded,
+	}
+
+	status := p.GetTaskStatus()
+	for name, s := range expects {
+		if status[name] != s {
+			t.Fatalf("task %s status:%s,expect:%s", name, status[name], s)
+		}
+	}
+}
+
+// TestRunnerContext test runner timeout cancel the running task
+func TestRunnerContext(t *testing.T) {
+	p := New(WithTimeout(50 * time.Millisecond))
+
+	stopped := make(chan struct{})
+	p.AddContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+	p.AddContext(func(ctx context.Context) error {
+		t.Error("the task after timeout should not run")
+		return nil
+	})
+
+	if err := p.Start(); err != ErrTimeout {
+		t.Fatalf("runner should timeout,err:%v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the running task should be canceled after runner timeout")
+	}
+
+	status := p.GetTaskStatus()
+	if status["task-0"] != StatusFailed || status["task-1"] != StatusSkipped {
+		t.Fatalf("invalid task status: %v", status)
+	}
+}
+
+// TestRunnerTaskTimeout test per task timeout
+func TestRunnerTaskTimeout(t *testing.T) {
+	p := New(WithTaskTimeout(time.Second))
+	p.AddNamedContext("slow", func(ctx context.Context) error {
+		// the task ignores ctx,the runner does not wait for it.
+		time.Sleep(500 * time.Millisecond)
+		return nil
+	})
+	p.SetTaskTimeout("slow", 20*time.Millisecond)
+
+	err := p.Start()
+	if !errors.Is(err, ErrTaskTimeout) {
+		t.Fatalf("the task should timeout,err:%v", err)
+	}
+}
+
+// TestRunnerTaskTimeoutBound test the timeout task which ignores ctx
+// still holds the parallelism until it exits
+func TestRunnerTaskTimeoutBound(t *testing.T) {
+	var (
+		mu               sync.Mutex
+		running, maxRuns int
+	)
+
+	p := New(WithTaskTimeout(20 * time.Millisecond))
+	for i := 0; i < 3; i++ {
+		p.Add(func() error {
+			mu.Lock()
+			running++
+			if running > maxRuns {
+				maxRuns = running
+			}
+			mu.Unlock()
+
+			// the task ignores ctx
+			time.Sleep(60 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	err := p.Start()
+	if !errors.Is(err, ErrTaskTimeout) {
+		t.Fatalf("the tasks should timeout,err:%v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxRuns != 1 || running != 0 {
+		t.Fatalf("the tasks should run one by one and exit before Start returns,max:%d running:%d", maxRuns, running)
+	}
+}
+
+// TestRunnerGracePeriod test Start waits the running tasks after runner timeout
+func TestRunnerGracePeriod(t *testing.T) {
+	exited := make(chan struct{})
+	p := New(WithTimeout(10*time.Millisecond), WithGracePeriod(time.Second))
+	p.Add(func() error {
+		time.Sleep(50 * time.Millisecond)
+		close(exited)
+		return nil
+	})
+
+	if err := p.Start(); err != ErrTimeout {
+		t.Fatalf("runner should timeout,err:%v", err)
+	}
+
+	select {
+	case <-exited:
+	default:
+		t.Fatal("Start should wait the running task exit in grace period")
+	}
+}
+
+// TestRunnerErrors test aggregated task errors
+func TestRunnerErrors(t *testing.T) {
+	errA := errors.New("task a error")
+	p := New(WithParallelism(2))
+	p.AddNamed("a", func() error {
+		return errA
+	})
+	p.AddNamed("b", func() error {
+		return &os.PathError{Op: "open", Path: "b", Err: os.ErrNotExist}
+	})
+	p.AddNamed("c", func() error {
+		return nil
+	})
+
+	err := p.Start()
+	log.Println("error: ", err)
+
+	var errs TaskErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("start should return TaskErrors,err:%v", err)
+	}
+
+	if errs[0].Name != "a" || errs[1].Name != "b" {
+		t.Fatalf("task errors should be sorted by index: %v", errs)
+	}
+
+	if !errors.Is(err, errA) || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("errors.Is should match every task error,err:%v", err)
+	}
+
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "b" {
+		t.Fatalf("errors.As should find the task error,err:%v", err)
+	}
+
+	if errs.ByName("a") != errA || errs.ByIndex(1) != pathErr || errs.ByName("c") != nil {
+		t.Fatalf("invalid task errors lookup: %v", errs)
+	}
+}
+
+// TestRunnerFailFast test fail fast error policy
+func TestRunnerFailFast(t *testing.T) {
+	errA := errors.New("task a error")
+	canceled := make(chan struct{})
+	p := New(WithParallelism(2), WithErrorPolicy(FailFast))
+	p.AddNamedContext("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	})
+	p.AddNamed("a", func() error {
+		return errA
+	})
+	p.AddNamed("c", func() error {
+		t.Error("the task after fail fast should not run")
+		return nil
+	})
+
+	err := p.Start()
+	log.Println("error: ", err)
+	if !errors.Is(err, errA) {
+		t.Fatalf("start should return the task error,err:%v", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the running task should be canceled after fail fast")
+	}
+
+	status := p.GetTaskStatus()
+	if status["a"] != StatusFailed || status["slow"] != StatusSkipped || status["c"] != StatusSkipped {
+		t.Fatalf("invalid task status: %v", status)
+	}
+
+	// the canceled sibling is not the cause of the failure.
+	var errs TaskErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs.ByName("a") == nil {
+		t.Fatalf("only the failed task should be reported,err:%v", err)
+	}
+
+	if all := p.GetAllErrors(); len(all) != 1 {
+		t.Fatalf("the canceled sibling should not be recorded,all errors:%v", all)
+	}
+}
+
 /**
 2020/05/23 20:30:05 正在执行任务19997
 [runner] 2020/05/23 20:30:05 current run task id:  19998
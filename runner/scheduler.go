@@ -0,0 +1,226 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoNextRun cron表达式没有下一次触发时间
+var ErrNoNextRun = errors.New("cron schedule has no next run time")
+
+// Clock 时钟接口，方便测试时注入模拟时钟
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock 系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// OverlapPolicy 上一次触发的runner还没有执行完毕时，新的触发的处理策略
+type OverlapPolicy int
+
+const (
+	// OverlapSkip 跳过本次触发，默认策略
+	OverlapSkip OverlapPolicy = iota
+
+	// OverlapQueue 本次触发排队，等上一次执行完毕后再执行
+	OverlapQueue
+
+	// OverlapReplace 取消正在执行的runner，执行完毕后立即开始本次触发
+	OverlapReplace
+)
+
+// Scheduler 按照cron表达式周期性的执行runner
+// 每次触发都会通过factory创建一个新的runner，同一时刻只有一个runner在执行
+type Scheduler struct {
+	schedule *Schedule
+	factory  func() *Runner
+	clock    Clock
+	overlap  OverlapPolicy
+	logger   Logger
+	onResult func(at time.Time, err error)
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc // 取消正在执行的runner，为nil时表示没有runner在执行
+	pending []time.Time        // 排队等待执行的触发时间
+	wg      sync.WaitGroup
+}
+
+// SchedulerOption 采用func Option功能模式为Scheduler添加参数
+type SchedulerOption func(s *Scheduler)
+
+// WithClock 设置Scheduler的时钟，默认为系统时钟
+func WithClock(c Clock) SchedulerOption {
+	return func(s *Scheduler) {
+		s.clock = c
+	}
+}
+
+// WithOverlapPolicy 设置重叠触发的处理策略，默认OverlapSkip
+func WithOverlapPolicy(policy OverlapPolicy) SchedulerOption {
+	return func(s *Scheduler) {
+		s.overlap = policy
+	}
+}
+
+// WithSchedulerLogger 设置Scheduler打印日志的句柄
+func WithSchedulerLogger(l Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		s.logger = l
+	}
+}
+
+// WithResultHandler 设置每次runner执行完毕后的回调，at为本次触发的时间
+func WithResultHandler(fn func(at time.Time, err error)) SchedulerOption {
+	return func(s *Scheduler) {
+		s.onResult = fn
+	}
+}
+
+// NewScheduler 创建一个Scheduler，spec为cron表达式，参考ParseCron
+// factory在每次触发时调用，返回本次需要执行的runner
+func NewScheduler(spec string, factory func() *Runner, opts ...SchedulerOption) (*Scheduler, error) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Scheduler{
+		schedule: schedule,
+		factory:  factory,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	if s.clock == nil {
+		s.clock = realClock{}
+	}
+
+	if s.logger == nil {
+		s.logger = log.New(os.Stdout, "", log.LstdFlags)
+	}
+
+	return s, nil
+}
+
+// Run 按照cron表达式触发runner，阻塞直到ctx取消
+// ctx取消后，正在执行的runner也会被取消，等待其退出后返回ctx.Err()
+func (s *Scheduler) Run(ctx context.Context) error {
+	defer s.wg.Wait()
+
+	next := s.schedule.Next(s.clock.Now())
+	for {
+		if next.IsZero() {
+			s.logger.Println(ErrNoNextRun)
+			return ErrNoNextRun
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(next.Sub(s.clock.Now())):
+			s.trigger(ctx, next)
+		}
+
+		// 以本次触发时间为基准，避免时钟提前唤醒时重复触发
+		now := s.clock.Now()
+		if now.Before(next) {
+			now = next
+		}
+
+		next = s.schedule.Next(now)
+	}
+}
+
+// trigger 处理一次触发，根据重叠策略决定是否执行runner
+func (s *Scheduler) trigger(ctx context.Context, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel == nil {
+		s.start(ctx, at)
+		return
+	}
+
+	switch s.overlap {
+	case OverlapQueue:
+		s.pending = append(s.pending, at)
+	case OverlapReplace:
+		s.logger.Println("cancel the running runner, trigger at: ", at)
+		s.pending = append(s.pending[:0], at)
+		s.cancel()
+	default:
+		s.logger.Println("the runner is still running, skip trigger at: ", at)
+	}
+}
+
+// start 在独立的goroutine中执行runner，执行完毕后继续执行排队的触发
+// 调用时需要持有s.mu
+func (s *Scheduler) start(ctx context.Context, at time.Time) {
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			s.exec(runCtx, at)
+			cancel()
+
+			s.mu.Lock()
+			if len(s.pending) == 0 || ctx.Err() != nil {
+				s.cancel = nil
+				s.pending = nil
+				s.mu.Unlock()
+				return
+			}
+
+			at = s.pending[0]
+			s.pending = s.pending[1:]
+			runCtx, cancel = context.WithCancel(ctx)
+			s.cancel = cancel
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// exec 创建并执行本次触发的runner
+func (s *Scheduler) exec(ctx context.Context, at time.Time) {
+	var err error
+	defer func() {
+		if e := recover(); e != nil {
+			s.logger.Println("exec runner panic: ", e)
+			err = fmt.Errorf("exec runner panic: %v", e)
+		}
+
+		if s.onResult != nil {
+			s.onResult(at, err)
+		}
+	}()
+
+	r := s.factory()
+	if r == nil {
+		return
+	}
+
+	s.logger.Println("start runner, trigger at: ", at)
+	err = r.StartContext(ctx)
+	s.logger.Println("runner complete, trigger at: ", at, "error: ", err)
+}
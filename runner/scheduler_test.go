@@ -0,0 +1,206 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock 模拟时钟，只有调用Advance时时间才会流逝
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	added   chan struct{}
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, added: make(chan struct{}, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	c.added <- struct{}{}
+	return ch
+}
+
+// Wait 等待scheduler开始等待下一次触发
+func (c *fakeClock) Wait(t *testing.T) {
+	select {
+	case <-c.added:
+	case <-time.After(time.Second):
+		t.Fatal("the scheduler is not waiting for the clock")
+	}
+}
+
+// Advance 等待scheduler开始等待下一次触发，然后将时间推进d
+func (c *fakeClock) Advance(t *testing.T, d time.Duration) {
+	c.Wait(t)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+
+		w.ch <- c.now
+	}
+
+	c.waiters = waiters
+}
+
+type schedulerResult struct {
+	at  time.Time
+	err error
+}
+
+// startScheduler 每秒触发一次，返回每次runner执行的结果和停止scheduler的函数
+func startScheduler(t *testing.T, clock *fakeClock, policy OverlapPolicy,
+	task func(ctx context.Context) error) (<-chan schedulerResult, func()) {
+	results := make(chan schedulerResult, 10)
+	s, err := NewScheduler("* * * * * *", func() *Runner {
+		r := New()
+		r.AddContext(task)
+		return r
+	}, WithClock(clock), WithOverlapPolicy(policy), WithResultHandler(func(at time.Time, err error) {
+		results <- schedulerResult{at: at, err: err}
+	}))
+	if err != nil {
+		t.Fatalf("create scheduler error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	return results, func() {
+		cancel()
+		if err := <-done; err != context.Canceled {
+			t.Fatalf("scheduler should stop by ctx,err: %v", err)
+		}
+	}
+}
+
+func waitResult(t *testing.T, results <-chan schedulerResult) schedulerResult {
+	select {
+	case res := <-results:
+		return res
+	case <-time.After(time.Second):
+		t.Fatal("the runner is not complete")
+		return schedulerResult{}
+	}
+}
+
+// TestSchedulerSkip test the overlapped trigger is skipped
+func TestSchedulerSkip(t *testing.T) {
+	start := time.Date(2020, 5, 23, 20, 30, 0, 0, time.Local)
+	clock := newFakeClock(start)
+	release := make(chan struct{})
+	results, stop := startScheduler(t, clock, OverlapSkip, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	clock.Advance(t, time.Second)
+	clock.Advance(t, time.Second)
+	clock.Advance(t, time.Second)
+
+	// 确保最后一次触发已经处理完毕
+	clock.Wait(t)
+	close(release)
+
+	res := waitResult(t, results)
+	if !res.at.Equal(start.Add(time.Second)) || res.err != nil {
+		t.Fatalf("invalid runner result: %v", res)
+	}
+
+	stop()
+	if len(results) != 0 {
+		t.Fatal("the overlapped trigger should be skipped")
+	}
+}
+
+// TestSchedulerQueue test the overlapped trigger runs after the previous one
+func TestSchedulerQueue(t *testing.T) {
+	start := time.Date(2020, 5, 23, 20, 30, 0, 0, time.Local)
+	clock := newFakeClock(start)
+	release := make(chan struct{}, 3)
+	results, stop := startScheduler(t, clock, OverlapQueue, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	clock.Advance(t, time.Second)
+	clock.Advance(t, time.Second)
+	clock.Advance(t, time.Second)
+	for i := 1; i <= 3; i++ {
+		release <- struct{}{}
+		res := waitResult(t, results)
+		if !res.at.Equal(start.Add(time.Duration(i)*time.Second)) || res.err != nil {
+			t.Fatalf("invalid runner result: %v", res)
+		}
+	}
+
+	stop()
+}
+
+// TestSchedulerReplace test the overlapped trigger cancels the running runner
+func TestSchedulerReplace(t *testing.T) {
+	start := time.Date(2020, 5, 23, 20, 30, 0, 0, time.Local)
+	clock := newFakeClock(start)
+	release := make(chan struct{})
+	results, stop := startScheduler(t, clock, OverlapReplace, func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-release:
+			return nil
+		}
+	})
+
+	clock.Advance(t, time.Second)
+	clock.Advance(t, time.Second)
+
+	res := waitResult(t, results)
+	if !res.at.Equal(start.Add(time.Second)) || !errors.Is(res.err, context.Canceled) {
+		t.Fatalf("the running runner should be canceled: %v", res)
+	}
+
+	close(release)
+	res = waitResult(t, results)
+	if !res.at.Equal(start.Add(2*time.Second)) || res.err != nil {
+		t.Fatalf("invalid runner result: %v", res)
+	}
+
+	stop()
+}
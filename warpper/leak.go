@@ -0,0 +1,102 @@
+package wrapper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// tracker tracks the labels of wrapped goroutines still running
+type tracker struct {
+	mu      sync.Mutex
+	seq     int
+	running map[int]string
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		running: make(map[int]string),
+	}
+}
+
+// start records a running goroutine,the goroutine without label is named by its sequence.
+func (t *tracker) start(label string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seq++
+	if label == "" {
+		label = fmt.Sprintf("goroutine-%d", t.seq)
+	}
+
+	t.running[t.seq] = label
+	return t.seq
+}
+
+func (t *tracker) finish(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.running, id)
+}
+
+// labels returns the sorted labels of running goroutines
+func (t *tracker) labels() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	labels := make([]string, 0, len(t.running))
+	for _, label := range t.running {
+		labels = append(labels, label)
+	}
+
+	sort.Strings(labels)
+	return labels
+}
+
+// waitContext wait the done chan closed or ctx done,
+// returns the labels of goroutines still running when ctx done.
+func (t *tracker) waitContext(ctx context.Context, done <-chan struct{}) []string {
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return t.labels()
+	}
+}
+
+// waitTimeout wait with timeout by WaitContext
+func waitTimeout(w TimeoutWaiter, d time.Duration) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	return w.WaitContext(ctx)
+}
+
+// TB the subset of testing.TB used by VerifyNoLeaks
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Cleanup(fn func())
+}
+
+// VerifyNoLeaks asserts that all goroutines wrapped by w finish
+// within timeout after the test finishes,it reports the leaked labels by t.Errorf.
+// w should implement TimeoutWaiter,otherwise it is reported by t.Errorf.
+func VerifyNoLeaks(t TB, w Wrapper, timeout time.Duration) {
+	t.Helper()
+
+	waiter, ok := w.(TimeoutWaiter)
+	if !ok {
+		t.Errorf("wrapper %T does not implement TimeoutWaiter", w)
+		return
+	}
+
+	t.Cleanup(func() {
+		if running := waiter.WaitTimeout(timeout); len(running) > 0 {
+			t.Errorf("wrapper goroutines leak after %v: %v", timeout, running)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package wrapper
+
+import (
+	"fmt"
+	"log"
+	"testing"
+	"time"
+)
+
+// mockTB records the errors and cleanups of VerifyNoLeaks
+type mockTB struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (m *mockTB) Helper() {}
+
+func (m *mockTB) Errorf(format string, args ...interface{}) {
+	m.errors = append(m.errors, fmt.Sprintf(format, args...))
+}
+
+func (m *mockTB) Cleanup(fn func()) {
+	m.cleanups = append(m.cleanups, fn)
+}
+
+func (m *mockTB) finish() {
+	for i := len(m.cleanups) - 1; i >= 0; i-- {
+		m.cleanups[i]()
+	}
+}
+
+func TestWrapperWaitTimeout(t *testing.T) {
+	for _, wrapType := range []WrapType{WgWrapper, ChWrapper, GroupWrapper} {
+		w := New(wrapType, WithBufCap(2))
+		release := make(chan struct{})
+		w.(LabelWrapper).WrapLabel("hang", func() {
+			<-release
+		})
+		w.Wrap(func() {
+			log.Println("1111")
+		})
+
+		running := w.(TimeoutWaiter).WaitTimeout(20 * time.Millisecond)
+		log.Println("running goroutines: ", running)
+		if len(running) != 1 || running[0] != "hang" {
+			t.Fatalf("wrap type %d should report the running goroutine: %v", wrapType, running)
+		}
+
+		tb := &mockTB{}
+		VerifyNoLeaks(tb, w, 20*time.Millisecond)
+		tb.finish()
+		if len(tb.errors) != 1 {
+			t.Fatalf("wrap type %d should report the leaked goroutine: %v", wrapType, tb.errors)
+		}
+
+		close(release)
+		if running := w.(TimeoutWaiter).WaitTimeout(time.Second); running != nil {
+			t.Fatalf("wrap type %d all goroutine should finish: %v", wrapType, running)
+		}
+	}
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	w := New(WgWrapper).(LabelWrapper)
+	VerifyNoLeaks(t, w, time.Second)
+
+	for i := 0; i < 10; i++ {
+		index := i
+		w.WrapLabel(fmt.Sprintf("job-%d", index), func() {
+			time.Sleep(10 * time.Millisecond)
+			log.Printf("current index: %d\n", index)
+		})
+	}
+}
+
+func TestVerifyNoLeaksUnsupported(t *testing.T) {
+	// only the Wrapper methods are promoted,WaitTimeout is not supported.
+	w := struct{ Wrapper }{New(WgWrapper)}
+
+	tb := &mockTB{}
+	VerifyNoLeaks(tb, w, time.Second)
+	if len(tb.errors) != 1 || len(tb.cleanups) != 0 {
+		t.Fatalf("the wrapper without WaitTimeout should be reported: %v", tb.errors)
+	}
+}
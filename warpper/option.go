@@ -4,6 +4,9 @@ package wrapper
 type Options struct {
 	BufCap       int
 	RecoveryFunc func()
+	PanicHandler func(p *PanicError)
+	Limit        int  // the max number of concurrent goroutines of GroupWrapper
+	AllErrors    bool // GroupWrapper collects all errors instead of the first one
 }
 
 // Options optional function
@@ -16,9 +19,33 @@ func WithRecover(recoveryFunc func()) Option {
 	}
 }
 
+// WithPanicHandler set the panic handler,it receives the panic value,
+// stack trace and the label of goroutine,the default handler logs them.
+// It does not work when the legacy recover func is set by WithRecover.
+func WithPanicHandler(fn func(p *PanicError)) Option {
+	return func(o *Options) {
+		o.PanicHandler = fn
+	}
+}
+
 // WithBufCap set buf cap
 func WithBufCap(c int) Option {
 	return func(o *Options) {
 		o.BufCap = c
 	}
 }
+
+// WithLimit set the max number of concurrent goroutines,only for GroupWrapper
+func WithLimit(n int) Option {
+	return func(o *Options) {
+		o.Limit = n
+	}
+}
+
+// WithAllErrors make GroupWrapper wait all goroutines and return all errors,
+// the sibling goroutines are not canceled on error,only for GroupWrapper
+func WithAllErrors() Option {
+	return func(o *Options) {
+		o.AllErrors = true
+	}
+}
@@ -0,0 +1,95 @@
+package wrapper
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicError the panic recovered from the wrapped goroutine
+type PanicError struct {
+	Label string      // the label passed to WrapLabel
+	Value interface{} // the value passed to panic
+	Stack []byte      // the stack trace of the goroutine when panic
+}
+
+// Error implements error interface.
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("wrapper goroutine %q panic: %v", p.Label, p.Value)
+}
+
+// recoverer recovers the panics of wrapped goroutines and collects them as errors
+type recoverer struct {
+	recoveryFunc func()
+	panicHandler func(p *PanicError)
+
+	mu   sync.Mutex
+	errs []error
+}
+
+func newRecoverer(option *Options) *recoverer {
+	r := &recoverer{
+		recoveryFunc: option.RecoveryFunc,
+		panicHandler: option.PanicHandler,
+	}
+
+	if r.panicHandler == nil {
+		r.panicHandler = defaultPanicHandler
+	}
+
+	return r
+}
+
+// call exec fn and returns the recovered panic as *PanicError,
+// the legacy RecoveryFunc is deferred directly and the panic is not returned.
+func (r *recoverer) call(label string, fn func()) (err error) {
+	if r.recoveryFunc != nil {
+		defer r.recoveryFunc()
+		fn()
+		return nil
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			p := &PanicError{Label: label, Value: e, Stack: debug.Stack()}
+			r.panicHandler(p)
+			err = p
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+// run exec fn and collects the recovered panic
+func (r *recoverer) run(label string, fn func()) {
+	if err := r.call(label, fn); err != nil {
+		r.mu.Lock()
+		r.errs = append(r.errs, err)
+		r.mu.Unlock()
+	}
+}
+
+// err returns the collected panics,nil if no panic,
+// the *PanicError if only one panic,otherwise MultiError.
+func (r *recoverer) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch len(r.errs) {
+	case 0:
+		return nil
+	case 1:
+		return r.errs[0]
+	default:
+		errs := make(MultiError, len(r.errs))
+		copy(errs, r.errs)
+		return errs
+	}
+}
+
+// defaultPanicHandler default panic handler.
+func defaultPanicHandler(p *PanicError) {
+	log.Printf("wrapper exec recover label:%s panic:%v\nstack:%s\n", p.Label, p.Value, p.Stack)
+}
@@ -0,0 +1,61 @@
+package wrapper
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"testing"
+)
+
+func TestWrapperPanicHandler(t *testing.T) {
+	for _, wrapType := range []WrapType{WgWrapper, ChWrapper, GroupWrapper} {
+		var (
+			mu     sync.Mutex
+			labels []string
+		)
+
+		w := New(wrapType, WithBufCap(3), WithPanicHandler(func(p *PanicError) {
+			log.Printf("label:%s panic:%v\nstack:%s\n", p.Label, p.Value, p.Stack)
+			if len(p.Stack) == 0 {
+				t.Error("the panic stack should not be empty")
+			}
+
+			mu.Lock()
+			labels = append(labels, p.Label)
+			mu.Unlock()
+		})).(LabelWrapper)
+
+		w.WrapLabel("job-1", func() {
+			panic("mock panic job-1")
+		})
+		w.WrapLabel("job-2", func() {
+			log.Println("job-2")
+		})
+		w.WrapWithRecover(func() {
+			log.Println("no label")
+		})
+
+		err := w.WaitError()
+		log.Println("wait error: ", err)
+
+		var p *PanicError
+		if !errors.As(err, &p) || p.Label != "job-1" || p.Value != "mock panic job-1" {
+			t.Fatalf("wrap type %d wait should return the recovered panic,err: %v", wrapType, err)
+		}
+
+		if len(labels) != 1 || labels[0] != "job-1" {
+			t.Fatalf("wrap type %d panic handler should receive the label: %v", wrapType, labels)
+		}
+	}
+}
+
+func TestWrapperLegacyRecover(t *testing.T) {
+	w := New(WgWrapper, WithRecover(mockRecovery)).(LabelWrapper)
+	w.WrapLabel("legacy", func() {
+		panic("mock panic test")
+	})
+
+	if err := w.WaitError(); err != nil {
+		t.Fatalf("the panic recovered by legacy recover func should not be returned,err: %v", err)
+	}
+}
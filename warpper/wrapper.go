@@ -1,6 +1,9 @@
 package wrapper
 
-import "log"
+import (
+	"context"
+	"time"
+)
 
 // Wrapper wrap goroutine to run
 type Wrapper interface {
@@ -14,6 +17,31 @@ type Wrapper interface {
 	Wait()
 }
 
+// LabelWrapper the wrapper supports goroutine label and returns the recovered panics,
+// all wrappers created by New implement it.
+type LabelWrapper interface {
+	Wrapper
+
+	// WrapLabel safely execute func in goroutine with label,
+	// the label is reported by the recovered panic.
+	WrapLabel(label string, fn func())
+
+	// WaitError wait all goroutine finish and returns the recovered panics as errors
+	WaitError() error
+}
+
+// TimeoutWaiter the wrapper supports waiting with timeout,
+// all wrappers created by New implement it.
+type TimeoutWaiter interface {
+	// WaitTimeout wait all goroutine finish at most d,
+	// returns the labels of goroutines still running after timeout.
+	WaitTimeout(d time.Duration) []string
+
+	// WaitContext wait all goroutine finish until ctx done,
+	// returns the labels of goroutines still running after ctx done.
+	WaitContext(ctx context.Context) []string
+}
+
 var wrapperMap = map[WrapType]constructor{
 	WgWrapper: NewWgWrapper,
 	ChWrapper: NewChanWrapper,
@@ -38,6 +66,8 @@ const (
 	WgWrapper WrapType = iota
 	// ChWrapper chan wrapper
 	ChWrapper
+	// GroupWrapper errgroup style wrapper with concurrency limit,see Group
+	GroupWrapper
 )
 
 // Register register wrapper
@@ -49,10 +79,3 @@ func Register(wrapType WrapType, c constructor) {
 
 	wrapperMap[wrapType] = c
 }
-
-// defaultRecovery default recover func.
-func defaultRecovery() {
-	if e := recover(); e != nil {
-		log.Printf("wrapper exec recover:%v\n", e)
-	}
-}
@@ -1,12 +1,22 @@
 package wrapper
 
+import (
+	"context"
+	"sync"
+	"time"
+)
+
 var _ Wrapper = (*wrapChanImpl)(nil)
 
 // wrapChanImpl wrapper impl
 type wrapChanImpl struct {
-	bufCap       int
-	bufCh        chan struct{}
-	recoveryFunc func()
+	*recoverer
+	tracker *tracker
+	bufCap  int
+	bufCh   chan struct{}
+
+	waitMu   sync.Mutex
+	received int // the number of finished goroutines received by Wait
 }
 
 // NewChanWrapper create wrapChanImpl entity
@@ -25,11 +35,8 @@ func NewChanWrapper(opts ...Option) Wrapper {
 		panic("chan wrapper buf cap must be gt 0")
 	}
 
-	w.recoveryFunc = option.RecoveryFunc
-	if w.recoveryFunc == nil {
-		w.recoveryFunc = defaultRecovery
-	}
-
+	w.recoverer = newRecoverer(option)
+	w.tracker = newTracker()
 	w.bufCap = option.BufCap
 	w.bufCh = make(chan struct{}, w.bufCap)
 
@@ -38,28 +45,62 @@ func NewChanWrapper(opts ...Option) Wrapper {
 
 // Wrap exec func in goroutine without recover catch
 func (c *wrapChanImpl) Wrap(fn func()) {
+	id := c.tracker.start("")
 	go func() {
-		defer c.done()
+		defer c.done(id)
 		fn()
 	}()
 }
 
 // WrapWithRecover safely execute func in goroutine
 func (c *wrapChanImpl) WrapWithRecover(fn func()) {
+	c.WrapLabel("", fn)
+}
+
+// WrapLabel safely execute func in goroutine with label
+func (c *wrapChanImpl) WrapLabel(label string, fn func()) {
+	id := c.tracker.start(label)
 	go func() {
-		defer c.recoveryFunc()
-		defer c.done()
-		fn()
+		defer c.done(id)
+		c.run(label, fn)
 	}()
 }
 
 // Wait wait all goroutine finish
 func (c *wrapChanImpl) Wait() {
-	for i := 0; i < c.bufCap; i++ {
-		<-c.bufCh
+	c.WaitContext(context.Background())
+}
+
+// WaitError wait all goroutine finish and returns the recovered panics
+func (c *wrapChanImpl) WaitError() error {
+	c.Wait()
+	return c.err()
+}
+
+// WaitTimeout wait all goroutine finish at most d
+func (c *wrapChanImpl) WaitTimeout(d time.Duration) []string {
+	return waitTimeout(c, d)
+}
+
+// WaitContext wait all goroutine finish until ctx done
+// The finished goroutines received before ctx done are not waited again.
+func (c *wrapChanImpl) WaitContext(ctx context.Context) []string {
+	c.waitMu.Lock()
+	defer c.waitMu.Unlock()
+
+	for c.received < c.bufCap {
+		select {
+		case <-c.bufCh:
+			c.received++
+		case <-ctx.Done():
+			return c.tracker.labels()
+		}
 	}
+
+	return nil
 }
 
-func (c *wrapChanImpl) done() {
+func (c *wrapChanImpl) done(id int) {
+	c.tracker.finish(id)
 	c.bufCh <- struct{}{}
 }
@@ -0,0 +1,210 @@
+package wrapper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Wrapper = (*Group)(nil)
+
+func init() {
+	Register(GroupWrapper, NewGroupWrapper)
+}
+
+// Group errgroup style wrapper,it limits the number of concurrent goroutines,
+// cancels the sibling goroutines on the first error and collects the errors.
+type Group struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	sem       chan struct{}
+	allErrors bool
+	recoverer *recoverer
+	tracker   *tracker
+
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroupWrapper create Group as Wrapper,the context of Group is context.Background.
+func NewGroupWrapper(opts ...Option) Wrapper {
+	return NewGroup(context.Background(), opts...)
+}
+
+// NewGroup create Group with ctx,the ctx passed to each func is derived from it.
+func NewGroup(ctx context.Context, opts ...Option) *Group {
+	option := &Options{}
+	for _, o := range opts {
+		o(option)
+	}
+
+	g := &Group{
+		allErrors: option.AllErrors,
+		recoverer: newRecoverer(option),
+		tracker:   newTracker(),
+	}
+
+	if option.Limit > 0 {
+		g.sem = make(chan struct{}, option.Limit)
+	}
+
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	return g
+}
+
+// Context returns the context passed to each func,
+// it is canceled on the first error or after Wait returns.
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go exec fn in goroutine,it blocks until a goroutine slot is available when limit is set.
+// The first error cancels the context of sibling goroutines unless WithAllErrors is set.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	g.GoLabel("", fn)
+}
+
+// GoLabel exec fn in goroutine with label like Go,
+// the label is reported by WaitContext when the goroutine is still running.
+func (g *Group) GoLabel(label string, fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.wg.Add(1)
+	id := g.tracker.start(label)
+	go func() {
+		defer g.done()
+		defer g.tracker.finish(id)
+		if err := fn(g.ctx); err != nil {
+			g.setError(err)
+		}
+	}()
+}
+
+// Wrap exec func in goroutine without recover catch
+func (g *Group) Wrap(fn func()) {
+	g.Go(func(ctx context.Context) error {
+		fn()
+		return nil
+	})
+}
+
+// WrapWithRecover safely execute func in goroutine,
+// the recovered panic is returned as error by WaitError.
+func (g *Group) WrapWithRecover(fn func()) {
+	g.WrapLabel("", fn)
+}
+
+// WrapLabel safely execute func in goroutine with label,
+// the recovered panic is returned as error by WaitError.
+func (g *Group) WrapLabel(label string, fn func()) {
+	g.GoLabel(label, func(ctx context.Context) error {
+		return g.recoverer.call(label, fn)
+	})
+}
+
+// Wait wait all goroutine finish
+func (g *Group) Wait() {
+	_ = g.WaitError()
+}
+
+// WaitError wait all goroutine finish and returns the first error,
+// when WithAllErrors is set,it returns all errors as MultiError.
+func (g *Group) WaitError() error {
+	g.wg.Wait()
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 {
+		return nil
+	}
+
+	if !g.allErrors {
+		return g.errs[0]
+	}
+
+	errs := make(MultiError, len(g.errs))
+	copy(errs, g.errs)
+	return errs
+}
+
+// WaitTimeout wait all goroutine finish at most d
+func (g *Group) WaitTimeout(d time.Duration) []string {
+	return waitTimeout(g, d)
+}
+
+// WaitContext wait all goroutine finish until ctx done,
+// the context of Group is canceled after all goroutine finish.
+func (g *Group) WaitContext(ctx context.Context) []string {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		g.cancel()
+		close(done)
+	}()
+
+	return g.tracker.waitContext(ctx, done)
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+
+	g.wg.Done()
+}
+
+func (g *Group) setError(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 && !g.allErrors {
+		g.cancel()
+	}
+
+	if g.allErrors || len(g.errs) == 0 {
+		g.errs = append(g.errs, err)
+	}
+}
+
+// MultiError the errors returned by goroutines,
+// errors.Is and errors.As match any of them.
+type MultiError []error
+
+// Error implements error interface.
+func (m MultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any error matches target.
+func (m MultiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error that matches target.
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
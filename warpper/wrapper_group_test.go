@@ -0,0 +1,92 @@
+package wrapper
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWrapperGroup(t *testing.T) {
+	var running, maxRunning int32
+	g := New(GroupWrapper, WithLimit(3)).(*Group)
+	for i := 0; i < 20; i++ {
+		index := i
+		g.Go(func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+
+			log.Printf("current index: %d\n", index)
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		})
+	}
+
+	if err := g.WaitError(); err != nil {
+		t.Fatalf("wait error: %v", err)
+	}
+
+	if maxRunning > 3 {
+		t.Fatalf("the concurrent goroutines should not exceed limit,max: %d", maxRunning)
+	}
+}
+
+func TestWrapperGroupCancel(t *testing.T) {
+	errFirst := errors.New("first error")
+	g := NewGroup(context.Background())
+	g.Go(func(ctx context.Context) error {
+		return errFirst
+	})
+	g.Go(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return errors.New("the sibling goroutine should be canceled")
+		}
+	})
+
+	if err := g.WaitError(); err != errFirst {
+		t.Fatalf("wait should return the first error,err: %v", err)
+	}
+}
+
+func TestWrapperGroupAllErrors(t *testing.T) {
+	errA := errors.New("error a")
+	errB := errors.New("error b")
+	g := NewGroup(context.Background(), WithAllErrors())
+	g.Go(func(ctx context.Context) error {
+		return errA
+	})
+	g.Go(func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		if ctx.Err() != nil {
+			return errors.New("the sibling goroutine should not be canceled")
+		}
+
+		return errB
+	})
+	g.Wrap(func() {
+		log.Println("1111")
+	})
+
+	err := g.WaitError()
+	log.Println("wait error: ", err)
+
+	var errs MultiError
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("wait should return all errors,err: %v", err)
+	}
+
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("errors.Is should match every error,err: %v", err)
+	}
+}
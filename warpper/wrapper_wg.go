@@ -1,7 +1,9 @@
 package wrapper
 
 import (
+	"context"
 	"sync"
+	"time"
 )
 
 var _ Wrapper = (*wrapWgImpl)(nil)
@@ -9,7 +11,8 @@ var _ Wrapper = (*wrapWgImpl)(nil)
 // wrapWgImpl sync.WaitGroup wrap impl
 type wrapWgImpl struct {
 	sync.WaitGroup
-	recoveryFunc func()
+	*recoverer
+	tracker *tracker
 }
 
 // NewWgWrapper create wrapper entity
@@ -20,29 +23,57 @@ func NewWgWrapper(opts ...Option) Wrapper {
 		o(option)
 	}
 
-	w.recoveryFunc = option.RecoveryFunc
-	if w.recoveryFunc == nil {
-		w.recoveryFunc = defaultRecovery
-	}
-
+	w.recoverer = newRecoverer(option)
+	w.tracker = newTracker()
 	return w
 }
 
 // Wrap fn func in goroutine to run
 func (w *wrapWgImpl) Wrap(fn func()) {
 	w.Add(1)
+	id := w.tracker.start("")
 	go func() {
 		defer w.Done()
+		defer w.tracker.finish(id)
 		fn()
 	}()
 }
 
 // WrapWithRecover exec func with recover
 func (w *wrapWgImpl) WrapWithRecover(fn func()) {
+	w.WrapLabel("", fn)
+}
+
+// WrapLabel exec func with recover and label
+func (w *wrapWgImpl) WrapLabel(label string, fn func()) {
 	w.Add(1)
+	id := w.tracker.start(label)
 	go func() {
-		defer w.recoveryFunc()
 		defer w.Done()
-		fn()
+		defer w.tracker.finish(id)
+		w.run(label, fn)
+	}()
+}
+
+// WaitError wait all goroutine finish and returns the recovered panics
+func (w *wrapWgImpl) WaitError() error {
+	w.Wait()
+	return w.err()
+}
+
+// WaitTimeout wait all goroutine finish at most d
+func (w *wrapWgImpl) WaitTimeout(d time.Duration) []string {
+	return waitTimeout(w, d)
+}
+
+// WaitContext wait all goroutine finish until ctx done
+// The goroutine waiting for the WaitGroup exits after all goroutine finish.
+func (w *wrapWgImpl) WaitContext(ctx context.Context) []string {
+	done := make(chan struct{})
+	go func() {
+		w.Wait()
+		close(done)
 	}()
+
+	return w.tracker.waitContext(ctx, done)
 }
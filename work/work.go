@@ -14,20 +14,81 @@
 package work
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"runtime/debug"
 	"sync"
 )
 
+var (
+	// ErrPoolClosed 工作池已经关闭，不能再提交任务
+	ErrPoolClosed = errors.New("work pool is closed")
+
+	// ErrWorkerPanic worker执行过程中发生了panic
+	ErrWorkerPanic = errors.New("worker panic")
+)
+
 // Worker worker必须满足Task方法
 type Worker interface {
 	Task()
 }
 
+// ContextWorker 可以感知ctx取消并返回错误的worker
+// ctx在ShutdownContext超时后会被取消，worker应当尽快返回
+type ContextWorker interface {
+	TaskContext(ctx context.Context) error
+}
+
+// WorkerFunc 将普通函数转换为ContextWorker
+type WorkerFunc func(ctx context.Context) error
+
+// TaskContext 实现了ContextWorker接口
+func (f WorkerFunc) TaskContext(ctx context.Context) error {
+	return f(ctx)
+}
+
+// worker 将Worker适配为ContextWorker
+type worker struct {
+	w Worker
+}
+
+func (w worker) TaskContext(ctx context.Context) error {
+	w.w.Task()
+	return nil
+}
+
+// job 提交到工作池的一个任务
+type job struct {
+	w    ContextWorker
+	raw  interface{} // 提交的原始worker，用于错误处理和未完成任务的报告
+	done chan error  // Submit等待任务执行结果，Add提交时为nil
+}
+
+// finish 通知任务的执行结果
+func (j *job) finish(err error) {
+	if j.done != nil {
+		j.done <- err
+	}
+}
+
 // Pool提供一个goroutine池,可以完成任何已提交的worker任务
 type Pool struct {
-	work chan Worker
-	wg   sync.WaitGroup
+	work    chan *job
+	quit    chan struct{} // 关闭工作池的信号，代替关闭work通道，避免Add时panic
+	once    sync.Once
+	wg      sync.WaitGroup
+	ctx     context.Context // 传递给ContextWorker的ctx
+	cancel  context.CancelFunc
+	logger  Logger
+	onError func(w interface{}, err error)
+	onPanic func(w interface{}, value interface{}, stack []byte)
+
+	mu      sync.Mutex
+	running map[*job]struct{} // 正在执行的任务
+	panics  map[string]int64  // 每种worker类型发生panic的次数
 }
 
 // Logger log interface
@@ -37,49 +98,255 @@ type Logger interface {
 
 var LogEntry Logger = log.New(os.Stderr, "", log.LstdFlags)
 
+// Option 采用func Option功能模式为Pool添加参数
+type Option func(p *Pool)
+
+// WithLogger 设置工作池打印日志的句柄，默认为LogEntry
+func WithLogger(l Logger) Option {
+	return func(p *Pool) {
+		p.logger = l
+	}
+}
+
+// WithErrorHandler 设置worker返回错误时的回调，w为提交的原始worker
+// 默认打印日志，通过Submit提交的任务，错误同时会返回给调用者
+func WithErrorHandler(fn func(w interface{}, err error)) Option {
+	return func(p *Pool) {
+		p.onError = fn
+	}
+}
+
+// WithPanicHandler 设置worker发生panic时的回调，w为提交的原始worker
+// value为panic的值，stack为发生panic时的调用栈，默认打印日志
+func WithPanicHandler(fn func(w interface{}, value interface{}, stack []byte)) Option {
+	return func(p *Pool) {
+		p.onPanic = fn
+	}
+}
+
 // New 创建一个工作池
-func New(gNum int) *Pool {
+func New(gNum int, opts ...Option) *Pool {
 	p := &Pool{
-		work: make(chan Worker), // 无缓冲通道
+		work:    make(chan *job), // 无缓冲通道
+		quit:    make(chan struct{}),
+		running: make(map[*job]struct{}, gNum),
+		panics:  make(map[string]int64),
+	}
+
+	for _, o := range opts {
+		o(p)
+	}
+
+	if p.logger == nil {
+		p.logger = LogEntry
 	}
 
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
 	p.wg.Add(gNum) // 最大goroutine个数
 	for i := 0; i < gNum; i++ {
 		// 开启独立的goroutine来执行任务
-		go func(p *Pool) {
-			defer catchRecover()
+		go p.loop()
+	}
+
+	return p
+}
 
-			defer p.wg.Done() // 执行完毕后计数信号量减去1
+// loop 一直阻塞,直到从work通道中收到一个任务或者工作池关闭
+// worker发生panic后，当前goroutine退出，并重新启动一个goroutine代替它
+func (p *Pool) loop() {
+	defer p.wg.Done() // 执行完毕后计数信号量减去1
 
-			// for...range会一直阻塞,直到从work通道中收到一个Worker接口值
-			for w := range p.work {
-				w.Task() // 执行任务
+	for {
+		select {
+		case j := <-p.work:
+			if !p.exec(j) { // 执行任务
+				p.wg.Add(1)
+				go p.loop()
+				return
 			}
-		}(p)
+		case <-p.quit:
+			return
+		}
 	}
+}
 
-	return p
+// exec 执行任务，worker返回的错误交给错误处理回调
+// worker发生panic时返回false
+func (p *Pool) exec(j *job) (ok bool) {
+	p.mu.Lock()
+	p.running[j] = struct{}{}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.running, j)
+		p.mu.Unlock()
+
+		if e := recover(); e != nil {
+			p.panicked(j, e, debug.Stack())
+			j.finish(fmt.Errorf("%w: %v", ErrWorkerPanic, e))
+			ok = false
+		}
+	}()
+
+	err := j.w.TaskContext(p.ctx)
+
+	if err != nil {
+		if p.onError != nil {
+			p.onError(j.raw, err)
+		} else {
+			p.logger.Println("exec worker error: ", err)
+		}
+	}
+
+	j.finish(err)
+	return true
+}
+
+// panicked 记录worker发生panic的次数，并交给panic回调处理
+func (p *Pool) panicked(j *job, value interface{}, stack []byte) {
+	p.mu.Lock()
+	p.panics[fmt.Sprintf("%T", j.raw)]++
+	p.mu.Unlock()
+
+	if p.onPanic != nil {
+		// panic回调本身发生panic时只打印日志，保证任务结果能够返回，goroutine能够重启
+		defer func() {
+			if e := recover(); e != nil {
+				p.logger.Println("exec panic handler panic: ", e, "\nstack: ", string(debug.Stack()))
+			}
+		}()
+
+		p.onPanic(j.raw, value, stack)
+		return
+	}
+
+	p.logger.Println("exec worker panic: ", value, "\nstack: ", string(stack))
+}
+
+// PanicStats worker发生panic的统计
+type PanicStats struct {
+	Total  int64            // panic总次数，也是goroutine重启的次数
+	ByType map[string]int64 // 每种worker类型(%T)发生panic的次数
+}
+
+// PanicStats 返回worker发生panic的统计
+func (p *Pool) PanicStats() PanicStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := PanicStats{ByType: make(map[string]int64, len(p.panics))}
+	for typ, n := range p.panics {
+		stats.ByType[typ] = n
+		stats.Total += n
+	}
+
+	return stats
 }
 
 // Add 生产者:采用无缓冲通道提交任务到工作池
 // 当任务提交后，消费者就会立即执行任务，p.wg计数器数量减去1
 // w是一个接口值,必须是具体实现类型的一个实例指针
+// 工作池关闭后提交的任务会被丢弃
 func (p *Pool) Add(w Worker) {
-	p.work <- w
+	if err := p.add(context.Background(), &job{w: worker{w: w}, raw: w}); err != nil {
+		p.logger.Println("add worker error: ", err)
+	}
+}
+
+// AddContext 提交可以返回错误的worker，ctx用于控制等待空闲goroutine的时间
+// ctx取消时返回ctx.Err()，工作池关闭后返回ErrPoolClosed
+func (p *Pool) AddContext(ctx context.Context, w ContextWorker) error {
+	return p.add(ctx, &job{w: w, raw: w})
+}
+
+// Submit 提交worker并返回接收执行结果的通道
+// 提交失败时通道返回提交的错误，worker发生panic时返回包装了ErrWorkerPanic的错误
+func (p *Pool) Submit(ctx context.Context, w ContextWorker) <-chan error {
+	j := &job{w: w, raw: w, done: make(chan error, 1)}
+	if err := p.add(ctx, j); err != nil {
+		j.finish(err)
+	}
+
+	return j.done
 }
 
-// Shutdown 等待所有的goroutine执行完毕,它关闭了 work 通道
+func (p *Pool) add(ctx context.Context, j *job) error {
+	select {
+	case <-p.quit:
+		return ErrPoolClosed
+	default:
+	}
+
+	select {
+	case p.work <- j:
+		return nil
+	case <-p.quit:
+		return ErrPoolClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ShutdownError ShutdownContext超时后，还没有执行完毕的worker
+type ShutdownError struct {
+	Unfinished []interface{} // 提交的原始worker
+	Err        error         // ctx.Err()
+}
+
+// Error implements error interface.
+func (e *ShutdownError) Error() string {
+	names := make([]string, 0, len(e.Unfinished))
+	for _, w := range e.Unfinished {
+		names = append(names, fmt.Sprintf("%T", w))
+	}
+
+	return fmt.Sprintf("work pool shutdown: %v, %d unfinished workers: %v", e.Err, len(e.Unfinished), names)
+}
+
+// Unwrap 返回ctx.Err()
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// Shutdown 等待所有的goroutine执行完毕,它关闭了 quit 通道
 // 这会导致所有池里的 goroutine 停止工作
 // 调用pg.wg 的 Wait 方法,会等待所有 goroutine 终止
 func (p *Pool) Shutdown() {
-	close(p.work) // 关闭通道会让所有池里的goroutine全部停止
-	p.wg.Wait()   // 等待所有的goroutine执行完毕
-	LogEntry.Println("all goroutine task finish")
+	p.ShutdownContext(context.Background())
 }
 
-// catchRecover 捕获异常或者panic处理
-func catchRecover() {
-	if err := recover(); err != nil {
-		LogEntry.Println("exec worker error: ", err)
+// ShutdownContext 关闭工作池，等待正在执行的worker执行完毕
+// ctx取消时，取消传递给ContextWorker的ctx，返回*ShutdownError报告还没有执行完毕的worker
+func (p *Pool) ShutdownContext(ctx context.Context) error {
+	p.once.Do(func() {
+		close(p.quit) // 关闭通道会让所有池里的goroutine全部停止
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait() // 等待所有的goroutine执行完毕
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		p.logger.Println("all goroutine task finish")
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		err := &ShutdownError{Err: ctx.Err()}
+		for j := range p.running {
+			err.Unfinished = append(err.Unfinished, j.raw)
+		}
+
+		p.logger.Println(err)
+		return err
 	}
 }
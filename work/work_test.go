@@ -1,12 +1,22 @@
 package work
 
 import (
+	"context"
+	"errors"
 	"log"
 	"runtime"
 	"sync"
 	"testing"
+	"time"
 )
 
+type panicWorker struct{}
+
+// Task 实现了Worker接口
+func (w *panicWorker) Task() {
+	panic("panic worker")
+}
+
 type myName struct {
 	name  string
 	age   int
@@ -79,3 +89,171 @@ func TestWorkPool(t *testing.T) {
 
 	p.Shutdown()
 }
+
+// TestAddContext 所有goroutine都在忙时，AddContext在ctx超时后返回
+func TestAddContext(t *testing.T) {
+	p := New(1)
+	release := make(chan struct{})
+	if err := p.AddContext(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		<-release
+		return nil
+	})); err != nil {
+		t.Fatalf("add worker error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := p.AddContext(ctx, WorkerFunc(func(ctx context.Context) error {
+		return nil
+	}))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("add worker should timeout,err: %v", err)
+	}
+
+	close(release)
+	p.Shutdown()
+
+	if err := p.AddContext(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		return nil
+	})); err != ErrPoolClosed {
+		t.Fatalf("add worker after shutdown should fail,err: %v", err)
+	}
+}
+
+// TestSubmit 通过Submit获取worker返回的错误
+func TestSubmit(t *testing.T) {
+	errTask := errors.New("task error")
+
+	var handled error
+	p := New(2, WithErrorHandler(func(w interface{}, err error) {
+		log.Printf("worker: %T error: %v\n", w, err)
+		handled = err
+	}))
+
+	err := <-p.Submit(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		return errTask
+	}))
+	if err != errTask || handled != errTask {
+		t.Fatalf("submit should return the worker error,err: %v handled: %v", err, handled)
+	}
+
+	err = <-p.Submit(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		panic("worker panic")
+	}))
+	if !errors.Is(err, ErrWorkerPanic) {
+		t.Fatalf("submit should return ErrWorkerPanic,err: %v", err)
+	}
+
+	p.Shutdown()
+}
+
+// TestShutdownContext 超时后报告还没有执行完毕的worker
+func TestShutdownContext(t *testing.T) {
+	p := New(2)
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	p.AddContext(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		close(canceled)
+		return ctx.Err()
+	}))
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := p.ShutdownContext(ctx)
+	log.Println("shutdown error: ", err)
+
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) || len(shutdownErr.Unfinished) != 1 {
+		t.Fatalf("shutdown should report the unfinished worker,err: %v", err)
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("shutdown error should wrap ctx error,err: %v", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the running worker should be canceled after shutdown timeout")
+	}
+}
+
+// TestPanicRestart worker发生panic后，goroutine会被重新启动
+func TestPanicRestart(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		values []interface{}
+	)
+
+	p := New(1, WithPanicHandler(func(w interface{}, value interface{}, stack []byte) {
+		log.Printf("worker: %T panic: %v\nstack: %s\n", w, value, stack)
+		if len(stack) == 0 {
+			t.Error("the panic stack should not be empty")
+		}
+
+		mu.Lock()
+		values = append(values, value)
+		mu.Unlock()
+	}))
+
+	p.Add(&panicWorker{})
+	err := <-p.Submit(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		panic("func worker")
+	}))
+	if !errors.Is(err, ErrWorkerPanic) {
+		t.Fatalf("submit should return ErrWorkerPanic,err: %v", err)
+	}
+
+	// 唯一的goroutine已经panic两次，仍然可以执行新的任务
+	if err := <-p.Submit(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		return nil
+	})); err != nil {
+		t.Fatalf("the restarted goroutine should exec the worker,err: %v", err)
+	}
+
+	p.Shutdown()
+
+	stats := p.PanicStats()
+	log.Printf("panic stats: %+v\n", stats)
+	if stats.Total != 2 || stats.ByType["*work.panicWorker"] != 1 || stats.ByType["work.WorkerFunc"] != 1 {
+		t.Fatalf("invalid panic stats: %+v", stats)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(values) != 2 || values[0] != "panic worker" || values[1] != "func worker" {
+		t.Fatalf("the panic handler should receive the panic values: %v", values)
+	}
+}
+
+// TestPanicHandlerPanic panic回调本身发生panic时，任务结果仍然返回，goroutine仍然会被重新启动
+func TestPanicHandlerPanic(t *testing.T) {
+	p := New(1, WithPanicHandler(func(w interface{}, value interface{}, stack []byte) {
+		panic("panic handler")
+	}))
+
+	err := <-p.Submit(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		panic("func worker")
+	}))
+	if !errors.Is(err, ErrWorkerPanic) {
+		t.Fatalf("submit should return ErrWorkerPanic,err: %v", err)
+	}
+
+	if err := <-p.Submit(context.Background(), WorkerFunc(func(ctx context.Context) error {
+		return nil
+	})); err != nil {
+		t.Fatalf("the restarted goroutine should exec the worker,err: %v", err)
+	}
+
+	p.Shutdown()
+
+	if stats := p.PanicStats(); stats.Total != 1 {
+		t.Fatalf("invalid panic stats: %+v", stats)
+	}
+}
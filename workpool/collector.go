@@ -0,0 +1,98 @@
+package workpool
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// Collector exports the work pool statistics as prometheus metrics.
+type Collector struct {
+	pool          *Pool
+	queueDepth    *prometheus.Desc
+	workers       *prometheus.Desc
+	activeWorkers *prometheus.Desc
+	tasks         *prometheus.Desc
+	panics        *prometheus.Desc
+	latency       prometheus.Histogram
+}
+
+// NewCollector returns a prometheus collector of the pool,
+// name is used as the pool label to distinguish the metrics of different pools.
+// The collector needs to be registered, e.g. prometheus.MustRegister(c).
+func NewCollector(p *Pool, name string) *Collector {
+	labels := prometheus.Labels{"pool": name}
+	c := &Collector{
+		pool: p,
+		queueDepth: prometheus.NewDesc(
+			"workpool_queue_depth",
+			"Number of tasks waiting to run",
+			nil, labels,
+		),
+		workers: prometheus.NewDesc(
+			"workpool_workers",
+			"Number of workers",
+			nil, labels,
+		),
+		activeWorkers: prometheus.NewDesc(
+			"workpool_active_workers",
+			"Number of workers running a task",
+			nil, labels,
+		),
+		tasks: prometheus.NewDesc(
+			"workpool_tasks_total",
+			"Number of tasks by status",
+			[]string{"status"}, labels,
+		),
+		panics: prometheus.NewDesc(
+			"workpool_task_panics_total",
+			"Number of tasks panicked",
+			nil, labels,
+		),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        "workpool_task_duration_seconds",
+			Help:        "task exec duration distribution",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}),
+	}
+
+	p.addObserver(func(status taskStatus, d time.Duration) {
+		if status == taskSkipped {
+			return
+		}
+
+		c.latency.Observe(d.Seconds())
+	})
+
+	return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queueDepth
+	ch <- c.workers
+	ch <- c.activeWorkers
+	ch <- c.tasks
+	ch <- c.panics
+	c.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stats()
+	ch <- prometheus.MustNewConstMetric(c.queueDepth, prometheus.GaugeValue, float64(s.Queued))
+	ch <- prometheus.MustNewConstMetric(c.workers, prometheus.GaugeValue, float64(s.Workers))
+	ch <- prometheus.MustNewConstMetric(c.activeWorkers, prometheus.GaugeValue, float64(s.Running))
+	ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.CounterValue, float64(s.Submitted), "submitted")
+	ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.CounterValue, float64(s.Rejected), "rejected")
+	ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.CounterValue, float64(s.Succeeded), "succeeded")
+	ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.CounterValue, float64(s.Failed), "failed")
+	ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.CounterValue, float64(s.Panicked), "panicked")
+	ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.CounterValue, float64(s.Skipped), "skipped")
+	ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.CounterValue, float64(s.Retried), "retried")
+	ch <- prometheus.MustNewConstMetric(c.panics, prometheus.CounterValue, float64(s.Panicked))
+	c.latency.Collect(ch)
+}
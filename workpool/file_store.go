@@ -0,0 +1,182 @@
+package workpool
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+var _ Store = (*FileStore)(nil)
+
+// journal op
+const (
+	journalSave = "save"
+	journalAck  = "ack"
+)
+
+// journalEntry a line of the journal file.
+type journalEntry struct {
+	Op     string  `json:"op"`
+	ID     string  `json:"id,omitempty"`
+	Record *Record `json:"record,omitempty"`
+}
+
+// FileStore the file journal implementation of Store,
+// each save or ack is appended to the journal file as a json line,
+// and the journal file is compacted when the records are loaded.
+// The written data is not synced to disk,it survives the process crash
+// but may be lost when the os crashes.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileStore returns a file store with the journal file path.
+func NewFileStore(path string) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		path: path,
+		file: f,
+	}, nil
+}
+
+// Save implements Store.
+func (s *FileStore) Save(r *Record) error {
+	return s.append(&journalEntry{Op: journalSave, Record: r})
+}
+
+// Ack implements Store.
+func (s *FileStore) Ack(r *Record) error {
+	return s.append(&journalEntry{Op: journalAck, ID: r.ID})
+}
+
+// Load implements Store,the journal file is rewritten with the records not acked.
+func (s *FileStore) Load() ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.compact(records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Close close the journal file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// append write a journal entry to the file.
+func (s *FileStore) append(e *journalEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.file.Write(append(b, '\n'))
+	return err
+}
+
+// read returns the records not acked in the journal file by save order.
+func (s *FileStore) read() ([]*Record, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var (
+		ids     []string
+		records = make(map[string]*Record)
+	)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		e := &journalEntry{}
+		if err = json.Unmarshal(scanner.Bytes(), e); err != nil {
+			// the last line may be broken when the process crashed.
+			continue
+		}
+
+		switch e.Op {
+		case journalSave:
+			if e.Record != nil {
+				ids = append(ids, e.Record.ID)
+				records[e.Record.ID] = e.Record
+			}
+		case journalAck:
+			delete(records, e.ID)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := make([]*Record, 0, len(records))
+	for _, id := range ids {
+		if r, ok := records[id]; ok {
+			pending = append(pending, r)
+		}
+	}
+
+	return pending, nil
+}
+
+// compact rewrite the journal file with the records.
+func (s *FileStore) compact(records []*Record) error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, r := range records {
+		b, err := json.Marshal(&journalEntry{Op: journalSave, Record: r})
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		w.Write(append(b, '\n'))
+	}
+
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	// reopen the journal file after rename.
+	s.file.Close()
+	s.file, err = os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	return err
+}
@@ -0,0 +1,47 @@
+package workpool
+
+import (
+	"context"
+	"sync"
+)
+
+// Future is the handle of a submitted task,
+// it can be used to wait for the task to finish and get the task error.
+type Future struct {
+	done chan struct{}
+	once sync.Once
+	err  error
+}
+
+// newFuture returns a future which is not finished.
+func newFuture() *Future {
+	return &Future{
+		done: make(chan struct{}),
+	}
+}
+
+// Done returns a chan which is closed when the task is finished.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the task to finish and returns the task error,
+// a recovered panic will be returned as an error which wraps ErrTaskPanic.
+// If ctx is done before the task finished, ctx.Err() will be returned.
+func (f *Future) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// complete set the task error and mark the future finished.
+// Only the first call takes effect.
+func (f *Future) complete(err error) {
+	f.once.Do(func() {
+		f.err = err
+		close(f.done)
+	})
+}
@@ -0,0 +1,73 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSubmit(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(10), WithWorkerCap(3),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	mockErr := errors.New("mock error")
+	futures := make([]*Future, 0, 30)
+	go func() {
+		for i := 0; i < 10; i++ {
+			futures = append(futures,
+				p.Submit(NewTask(func() error {
+					return nil
+				})),
+				p.Submit(NewTask(func() error {
+					return mockErr
+				})),
+				p.Submit(NewTask(func() error {
+					panic("mock panic")
+				})),
+			)
+		}
+
+		for _, f := range futures {
+			<-f.Done()
+		}
+
+		p.Shutdown()
+	}()
+
+	p.Run()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i, f := range futures {
+		err := f.Wait(ctx)
+		switch i % 3 {
+		case 0:
+			if err != nil {
+				t.Fatalf("task %d should succeed,got err:%v", i, err)
+			}
+		case 1:
+			if !errors.Is(err, mockErr) {
+				t.Fatalf("task %d should return mock error,got err:%v", i, err)
+			}
+		case 2:
+			if !errors.Is(err, ErrTaskPanic) {
+				t.Fatalf("task %d should return panic error,got err:%v", i, err)
+			}
+		}
+	}
+
+	// the pool is closed,the future will be finished with ErrPoolClosed.
+	f := p.Submit(NewTask(func() error {
+		return nil
+	}))
+	if err := f.Wait(ctx); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("submit to closed pool should return ErrPoolClosed,got err:%v", err)
+	}
+}
@@ -3,42 +3,169 @@ package workpool
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
 
 // Task task struct.
 type Task struct {
-	fn func() error
+	ctx      context.Context                 // task context,the task will be skipped when it is done
+	fn       func(ctx context.Context) error // task exec func
+	timeout  time.Duration                   // task exec timeout,0 means no timeout
+	future   *Future                         // task result future,it is nil if the task is not submitted
+	priority int                             // task priority,the task with higher priority runs first
+	retry    *RetryPolicy                    // task retry policy,it overrides the pool retry policy
+	attempts int                             // the number of exec times of the task
+	name     string                          // the handler name of the named task
+	payload  []byte                          // the payload of the named task
+	record   *Record                         // the record of the named task in the store
+	group    string                          // the task group for rate limit
+}
+
+// TaskOption func option to change task.
+type TaskOption func(t *Task)
+
+// WithTaskTimeout set the max exec time of the task.
+// The context passed to the task func will be canceled after d.
+func WithTaskTimeout(d time.Duration) TaskOption {
+	return func(t *Task) {
+		t.timeout = d
+	}
 }
 
 // NewTask returns task,create a task entry.
-func NewTask(fn func() error) *Task {
-	return &Task{
-		fn: fn,
+func NewTask(fn func() error, opts ...TaskOption) *Task {
+	return NewTaskWithContext(context.Background(), func(ctx context.Context) error {
+		return fn()
+	}, opts...)
+}
+
+// NewTaskWithContext returns a context-aware task.
+// The task will be skipped if ctx is done before it runs,
+// and fn should return as soon as possible when ctx is done.
+func NewTaskWithContext(ctx context.Context, fn func(ctx context.Context) error, opts ...TaskOption) *Task {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	t := &Task{
+		ctx: ctx,
+		fn:  fn,
 	}
+
+	for _, o := range opts {
+		o(t)
+	}
+
+	return t
 }
 
-// run exec a task.
-func (t *Task) run(logEntry Logger) {
-	if t == nil {
-		return
+// run exec a task and returns the task status and error.
+// root is the pool root context,it will be canceled when the pool is shutdown.
+func (t *Task) run(root context.Context, logEntry Logger) (status taskStatus, err error) {
+	if err = t.ctx.Err(); err != nil {
+		logEntry.Println("skip task,context done: ", err)
+		return taskSkipped, err
 	}
 
+	ctx, cancel := t.context(root)
+	defer cancel()
+
 	defer func() {
 		if e := recover(); e != nil {
 			logEntry.Println("exec current task panic: ", e)
+			status, err = taskPanicked, fmt.Errorf("%w: %v", ErrTaskPanic, e)
 		}
 	}()
 
-	err := t.fn()
+	err = t.fn(ctx)
 	if err != nil {
 		logEntry.Println("exec task error: ", err)
+		return taskFailed, err
+	}
+
+	return taskSucceeded, nil
+}
+
+// finish complete the task future with err.
+func (t *Task) finish(err error) {
+	if t.future != nil {
+		t.future.complete(err)
 	}
 }
 
+// clone returns a copy of the task,the same task may be added many times,
+// so the pool exec a copy of the task to keep the exec state of each one.
+func (t *Task) clone() *Task {
+	c := *t
+	return &c
+}
+
+// context returns the context to exec task,
+// it is done when the task context is done, the task timeout
+// or the pool root context is canceled.
+// The context is derived from the pool root context and looks up values in the task context,
+// the deadline of the task context is applied to it directly,so the task sees the deadline
+// and context.DeadlineExceeded.
+// Only the task context which can be canceled needs a goroutine to forward its cancellation.
+func (t *Task) context(root context.Context) (context.Context, context.CancelFunc) {
+	var (
+		parent = valueContext{Context: root, values: t.ctx}
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	deadline, ok := t.ctx.Deadline()
+	if t.timeout > 0 {
+		if d := time.Now().Add(t.timeout); !ok || d.Before(deadline) {
+			deadline, ok = d, true
+		}
+	}
+
+	if ok {
+		ctx, cancel = context.WithDeadline(parent, deadline)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+
+	if t.ctx.Done() != nil {
+		go func() {
+			select {
+			case <-t.ctx.Done():
+				// the deadline of the task context is exceeded by ctx itself.
+				if !errors.Is(t.ctx.Err(), context.DeadlineExceeded) {
+					cancel()
+				}
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return ctx, cancel
+}
+
+// valueContext the root context with the values of the task context.
+type valueContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value looks up the root context first,so the cancellation of root context
+// is propagated directly,then looks up the task context.
+func (c valueContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+
+	return c.values.Value(key)
+}
+
 // Logger log record interface
 type Logger interface {
 	Println(args ...interface{})
@@ -49,18 +176,66 @@ type Pool struct {
 	// execInterval interval time after each task is executed
 	// interval default 10ms
 	execInterval   time.Duration
-	entryChan      chan *Task     // task entry chan
-	entryCap       int            // entry chan num
-	jobChan        chan *Task     // job chan
-	jobCap         int            // job chan num
-	workerCap      int            // worker chan num
-	logEntry       Logger         // logger interface
-	stop           chan struct{}  // stop sem
-	interrupt      chan os.Signal // interrupt signal
-	entryCloseWait time.Duration  // close entry chan wait time,default 5s
-	shutdownWait   time.Duration  // work pool shutdown wait time,default 3s
+	entryChan      chan *Task               // task entry chan
+	entryCap       int                      // entry chan num
+	jobChan        chan *Task               // job chan
+	jobCap         int                      // job chan num
+	workerCap      int                      // worker chan num
+	logEntry       Logger                   // logger interface
+	stop           chan struct{}            // stop sem
+	done           chan struct{}            // closed after the pool exit
+	interrupt      chan os.Signal           // interrupt signal
+	handleSignal   bool                     // listen the system interrupt signal,default true
+	entryCloseWait time.Duration            // close entry chan wait time,default 5s
+	shutdownWait   time.Duration            // work pool shutdown wait time,default 3s
+	priorityAging  time.Duration            // priority queue aging interval,default 1s
+	queueSize      int                      // max task num in the priority queue
+	minWorkers     int                      // min worker num,default workerCap
+	maxWorkers     int                      // max worker num,default minWorkers
+	scaleThreshold int                      // grow a worker when the task backlog exceeds it
+	idleTimeout    time.Duration            // the extra worker exit after idle timeout,default 1min
+	retire         chan struct{}            // retire sem for Resize
+	rejectPolicy   RejectPolicy             // reject policy when the entry chan is full
+	counters       counters                 // pool counters for statistics
+	observers      atomic.Value             // task observers,[]taskObserver
+	retryPolicy    *RetryPolicy             // retry policy for all tasks
+	deadLetter     func(t *Task, err error) // called after the task finally fails
+	store          Store                    // durable store of the named tasks
+	pending        []*Record                // records loaded from the store to replay
+	loadErr        error                    // the error of loading the records from the store
+	handlers       map[string]Handler       // handlers of the named tasks
+	limiter        *limiter                 // rate limiter shared across workers
+	groupRate      float64                  // rate limit of each task group
+	groupBurst     int                      // rate limit burst of each task group
+
+	mu        sync.Mutex          // protect the fields below
+	wg        sync.WaitGroup      // wait all workers exit
+	workerNum int                 // current worker num
+	workerSeq int                 // worker id sequence
+	running   bool                // the pool is running
+	closed    bool                // the job chan is closed
+	groups    map[string]*limiter // rate limiters of the task groups
+
+	// ctx is the pool root context,it will be canceled when the pool shutdown,
+	// so that the running tasks can stop cooperatively.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
+var (
+	// ErrPoolClosed the work pool is closed and no longer accepts task.
+	ErrPoolClosed = errors.New("work pool is closed")
+
+	// ErrTaskPanic the task throw a panic.
+	ErrTaskPanic = errors.New("task panic")
+
+	// ErrTaskInvalid the task is nil.
+	ErrTaskInvalid = errors.New("task is nil")
+
+	// ErrShutdownTimeout the work pool does not exit after shutdown wait time.
+	ErrShutdownTimeout = errors.New("work pool shutdown timeout")
+)
+
 var (
 	// defaultMaxEntryCap default max entry chan num.
 	defaultMaxEntryCap = 10000
@@ -106,6 +281,8 @@ func WithEntryCap(n int) Option {
 }
 
 // WithJobCap job chan number.
+// The job chan is unbuffered so that the tasks are scheduled by priority,
+// n is added to the capacity of the priority queue instead.
 func WithJobCap(n int) Option {
 	return func(p *Pool) {
 		p.jobCap = n
@@ -133,6 +310,14 @@ func WithEntryCloseWait(d time.Duration) Option {
 	}
 }
 
+// WithSignalHandling enable or disable the system interrupt signal handling,default true.
+// If it is disabled, the pool lifecycle can be driven by RunContext and Shutdown.
+func WithSignalHandling(enable bool) Option {
+	return func(p *Pool) {
+		p.handleSignal = enable
+	}
+}
+
 // WithShutdownWait change shutdown entryCloseWait time.
 func WithShutdownWait(d time.Duration) Option {
 	return func(p *Pool) {
@@ -146,8 +331,12 @@ func NewPool(opts ...Option) *Pool {
 		execInterval:   10 * time.Millisecond,
 		workerCap:      defaultMinWorker,
 		stop:           make(chan struct{}, 1),
+		done:           make(chan struct{}),
+		handleSignal:   true,
 		entryCloseWait: 5 * time.Second,
 		shutdownWait:   3 * time.Second,
+		priorityAging:  defaultPriorityAging,
+		idleTimeout:    defaultIdleTimeout,
 		interrupt:      make(chan os.Signal, 1),
 		logEntry:       dummyLogger, // default logger entry.
 	}
@@ -155,21 +344,46 @@ func NewPool(opts ...Option) *Pool {
 	// option functions.
 	p.apply(opts...)
 
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
 	if p.workerCap >= defaultMaxWorker {
 		p.workerCap = defaultMaxWorker
 	}
 
-	if p.jobCap == 0 {
-		// no buf for jobChan.
-		p.jobChan = make(chan *Task)
-	} else {
-		if p.jobCap >= defaultMaxJobCap {
-			p.jobCap = defaultMaxJobCap
-		}
+	if p.minWorkers <= 0 {
+		p.minWorkers = p.workerCap
+	}
 
-		p.jobChan = make(chan *Task, p.jobCap)
+	if p.minWorkers <= 0 {
+		p.minWorkers = 1
 	}
 
+	if p.minWorkers >= defaultMaxWorker {
+		p.minWorkers = defaultMaxWorker
+	}
+
+	if p.maxWorkers < p.minWorkers {
+		p.maxWorkers = p.minWorkers
+	}
+
+	if p.maxWorkers >= defaultMaxWorker {
+		p.maxWorkers = defaultMaxWorker
+	}
+
+	p.retire = make(chan struct{}, defaultMaxWorker)
+
+	// load the tasks not finished before restart,before the pool accepts any task,
+	// so the tasks added before Run will not be replayed.
+	p.pending, p.loadErr = p.load()
+
+	if p.jobCap >= defaultMaxJobCap {
+		p.jobCap = defaultMaxJobCap
+	}
+
+	// no buf for jobChan,the waiting tasks are buffered by the priority queue,
+	// otherwise the tasks in the job chan would run in FIFO order.
+	p.jobChan = make(chan *Task)
+
 	if p.entryCap == 0 {
 		// no buf for entryChan.
 		p.entryChan = make(chan *Task)
@@ -191,108 +405,223 @@ func (p *Pool) apply(opts ...Option) {
 }
 
 // AddTask add a task to p.entryChan.
+// If the entry chan is full,the task will be handled by the reject policy.
 func (p *Pool) AddTask(t *Task) {
 	if t == nil {
 		return
 	}
 
-	defer p.recovery()
-
-	select {
-	case <-p.stop:
-		return
-	default:
-		p.entryChan <- t
+	if err := p.offer(context.Background(), t.clone()); err != nil {
+		p.logEntry.Println("add task error: ", err)
 	}
 }
 
 // BatchAddTask batch add task to p.entryChan.
 func (p *Pool) BatchAddTask(t []*Task) {
-	defer p.recovery()
-
 	for k := range t {
 		if t[k] == nil {
 			continue
 		}
 
-		select {
-		case <-p.stop:
+		err := p.offer(context.Background(), t[k].clone())
+		if err == ErrPoolClosed {
 			return
-		default:
-			p.entryChan <- t[k]
+		}
+
+		if err != nil {
+			p.logEntry.Println("add task error: ", err)
 		}
 	}
 }
 
-// exec exec task from job chan.
-func (p *Pool) exec(id int, done chan struct{}) {
-	defer p.recovery()
+// Submit add a task to p.entryChan and returns a future to wait for the task result.
+// If the pool is closed, the future will be finished with ErrPoolClosed,
+// and if the task is rejected, the future will be finished with ErrPoolFull.
+func (p *Pool) Submit(t *Task) *Future {
+	f := newFuture()
+	if t == nil {
+		f.complete(ErrTaskInvalid)
+		return f
+	}
 
+	// the same task may be submitted many times,
+	// so each submitted task has its own future.
+	st := t.clone()
+	st.future = f
+	if err := p.offer(context.Background(), st); err != nil {
+		f.complete(err)
+	}
+
+	return f
+}
+
+// exec exec task from job chan.
+func (p *Pool) exec(id int) {
+	var retired bool
 	defer func() {
-		done <- est
+		if !retired {
+			p.mu.Lock()
+			p.workerNum--
+			p.mu.Unlock()
+		}
+
+		p.wg.Done()
 		p.logEntry.Println("current worker id: ", id, "will exit...")
 	}()
 
+	defer p.recovery()
+
+	var (
+		timer *time.Timer
+		idle  <-chan time.Time // nil chan if no idle timeout
+	)
+
+	if p.idleTimeout > 0 {
+		timer = time.NewTimer(p.idleTimeout)
+		defer timer.Stop()
+
+		idle = timer.C
+	}
+
 	// get task from JobChan to run.
-	for task := range p.jobChan {
-		task.run(p.logEntry)
-		p.logEntry.Println("current worker id: ", id)
+	for {
+		select {
+		case task, ok := <-p.jobChan:
+			if !ok {
+				return
+			}
+
+			p.runTask(id, task)
+			if timer != nil {
+				resetTimer(timer, p.idleTimeout)
+			}
+		case <-p.retire:
+			retired = true
+			return
+		case <-idle:
+			if p.retireIdle() {
+				retired = true
+				return
+			}
 
-		// interval time after each task is executed.
-		if p.execInterval > 0 {
-			time.Sleep(p.execInterval)
+			timer.Reset(p.idleTimeout)
 		}
 	}
 }
 
-// Run create workerCap goroutine to exec task.
+// runTask run a task in the worker.
+func (p *Pool) runTask(id int, task *Task) {
+	p.counters.queued.Add(-1)
+	p.process(task)
+	p.logEntry.Println("current worker id: ", id)
+
+	// interval time after each task is executed.
+	if p.execInterval > 0 {
+		time.Sleep(p.execInterval)
+	}
+}
+
+// Run create min workers goroutine to exec task,
+// it blocks until the pool exit by the interrupt signal or Shutdown.
 func (p *Pool) Run() {
+	p.RunContext(context.Background())
+}
+
+// RunContext create min workers goroutine to exec task,
+// it blocks until the pool exit by the interrupt signal, Shutdown or ctx done.
+func (p *Pool) RunContext(ctx context.Context) {
 	p.logEntry.Println("exec task begin...")
-	signal.Notify(p.interrupt, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, syscall.SIGHUP)
+	defer close(p.done)
 
-	doneBuf := make(chan struct{}, p.workerCap)
-	// create p.workerCap goroutine to do task
-	for i := 0; i < p.workerCap; i++ {
-		go p.exec(i+1, doneBuf)
+	if p.handleSignal {
+		signal.Notify(p.interrupt, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, syscall.SIGHUP)
+		defer signal.Stop(p.interrupt)
 	}
 
-	// throw entry chan task to JobChan
-	go func() {
-		defer close(p.jobChan)
+	// create p.minWorkers goroutine to do task
+	p.mu.Lock()
+	p.running = true
+	for p.workerNum < p.minWorkers {
+		p.spawn()
+	}
+	p.mu.Unlock()
 
-		// If the entry channel is closed, the block will be lifted until consumption is completed.
-		for task := range p.entryChan {
-			p.jobChan <- task
-		}
-	}()
+	// throw entry chan task to JobChan by priority.
+	// If the entry channel is closed, the block will be lifted until consumption is completed.
+	go p.schedule()
 
 	// listen interrupt signal for work pool graceful exit.
-	go func() {
-		// Block until we receive stop signal.
-		sig := <-p.interrupt
+	go p.listen(ctx)
+
+	// replay the tasks not finished before restart.
+	if len(p.pending) > 0 {
+		go p.replay()
+	}
+
+	// entryCloseWait all job chan task to finish.
+	p.wg.Wait()
+
+	p.logEntry.Println("work pool shutdown success")
+}
+
+// listen wait for the interrupt signal or ctx done,then close the entry chan.
+func (p *Pool) listen(ctx context.Context) {
+	// Block until we receive stop signal.
+	select {
+	case sig := <-p.interrupt:
 		p.logEntry.Println("recv signal: ", sig.String())
-		close(p.stop)
+	case <-ctx.Done():
+		p.logEntry.Println("context done: ", ctx.Err())
+	}
 
-		// Here you need to entryCloseWait for the task that has been sent to the entry chan
-		// to ensure that it can be sent successfully
-		ctx, cancel := context.WithTimeout(context.Background(), p.entryCloseWait)
-		defer cancel()
+	close(p.stop)
 
-		<-ctx.Done()
+	// Here you need to entryCloseWait for the task that has been sent to the entry chan
+	// to ensure that it can be sent successfully
+	wait, cancel := context.WithTimeout(context.Background(), p.entryCloseWait)
+	defer cancel()
 
-		close(p.entryChan)
-	}()
+	<-wait.Done()
 
-	// entryCloseWait all job chan task to finish.
-	for i := 0; i < p.workerCap; i++ {
-		<-doneBuf
+	close(p.entryChan)
+
+	// the queued tasks are drained with the live root context,
+	// cancel it to notify the running tasks to stop after shutdownWait.
+	timer := time.NewTimer(p.shutdownWait)
+	defer timer.Stop()
+
+	select {
+	case <-p.done:
+	case <-timer.C:
+		p.logEntry.Println("shutdown wait timeout,cancel the running tasks")
 	}
 
-	p.logEntry.Println("work pool shutdown success")
+	p.cancel()
+}
+
+// ShutdownError the pool does not exit after shutdown wait time,
+// it reports the tasks abandoned.
+type ShutdownError struct {
+	Queued  int64 // the number of tasks still waiting to run
+	Running int64 // the number of tasks still running
+}
+
+// Error implements error interface.
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("%s,abandoned tasks: %d queued,%d running", ErrShutdownTimeout, e.Queued, e.Running)
+}
+
+// Unwrap returns ErrShutdownTimeout.
+func (e *ShutdownError) Unwrap() error {
+	return ErrShutdownTimeout
 }
 
 // Shutdown If all task are sent to the task entry chan, you can call this method to exit smoothly.
-func (p *Pool) Shutdown() {
+// The queued tasks are drained first,the pool root context will be canceled after shutdownWait,
+// then the running context-aware tasks can stop cooperatively.
+// If the pool does not exit after the entry chan closed and shutdownWait,
+// a *ShutdownError will be returned.
+func (p *Pool) Shutdown() error {
 	// Create a deadline to manual exit entryCloseWait time.
 	ctx, cancel := context.WithTimeout(context.Background(), p.entryCloseWait)
 	defer cancel()
@@ -301,8 +630,31 @@ func (p *Pool) Shutdown() {
 	// until the timeout deadline.
 	<-ctx.Done()
 
-	p.interrupt <- syscall.SIGTERM
+	select {
+	case p.interrupt <- syscall.SIGTERM:
+	default:
+		// the pool is shutting down.
+	}
+
 	p.logEntry.Println("work pool will shutdown...")
+
+	p.mu.Lock()
+	running := p.running
+	p.mu.Unlock()
+	if !running {
+		return nil
+	}
+
+	timer := time.NewTimer(p.entryCloseWait + p.shutdownWait)
+	defer timer.Stop()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-timer.C:
+		s := p.Stats()
+		return &ShutdownError{Queued: s.Queued, Running: s.Running}
+	}
 }
 
 // recovery catch a recover.
@@ -1,8 +1,12 @@
 package workpool
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -68,6 +72,201 @@ func TestShutdown(t *testing.T) {
 	p.Run()
 }
 
+// startPool run the pool in a goroutine and wait for the pool to exec tasks,
+// the returned chan is closed after the pool exit.
+func startPool(p *Pool) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run()
+	}()
+
+	<-p.Submit(NewTask(func() error {
+		return nil
+	})).Done()
+
+	return done
+}
+
+func TestTaskWithContext(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(10), WithWorkerCap(2),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithShutdownWait(50*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	var skipped, timeout, canceled int32
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	go func() {
+		// the task context is done before the task runs,it will be skipped.
+		p.AddTask(NewTaskWithContext(ctx, func(ctx context.Context) error {
+			atomic.StoreInt32(&skipped, 1)
+			return nil
+		}))
+
+		// the task will be canceled after the task timeout.
+		p.AddTask(NewTaskWithContext(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				atomic.StoreInt32(&timeout, 1)
+			}
+
+			return ctx.Err()
+		}, WithTaskTimeout(20*time.Millisecond)))
+
+		// the running task will be canceled when the pool shutdown.
+		p.AddTask(NewTaskWithContext(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			atomic.StoreInt32(&canceled, 1)
+			return ctx.Err()
+		}))
+
+		p.Shutdown()
+	}()
+
+	p.Run()
+
+	if atomic.LoadInt32(&skipped) != 0 {
+		t.Fatal("the task with done context should be skipped")
+	}
+
+	if atomic.LoadInt32(&timeout) != 1 {
+		t.Fatal("the task should be canceled by timeout")
+	}
+
+	if atomic.LoadInt32(&canceled) != 1 {
+		t.Fatal("the running task should be canceled when pool shutdown")
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithSignalHandling(false),
+		WithEntryCloseWait(50*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var count int32
+	go func() {
+		for i := 0; i < 10; i++ {
+			p.AddTask(NewTask(func() error {
+				atomic.AddInt32(&count, 1)
+				return nil
+			}))
+		}
+	}()
+
+	// the pool exit after ctx done.
+	p.RunContext(ctx)
+
+	if n := atomic.LoadInt32(&count); n != 10 {
+		t.Fatalf("exec task num:%d,expect:10", n)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithSignalHandling(false),
+		WithEntryCloseWait(20*time.Millisecond),
+		WithShutdownWait(20*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	// the task ignores the context,so it will be abandoned after shutdown wait.
+	release := make(chan struct{})
+	p.AddTask(NewTask(func() error {
+		<-release
+		return nil
+	}))
+
+	err := p.Shutdown()
+	var se *ShutdownError
+	if !errors.As(err, &se) || !errors.Is(err, ErrShutdownTimeout) || se.Running != 1 {
+		t.Fatalf("shutdown should report the abandoned task,err:%v", err)
+	}
+
+	close(release)
+	<-done
+}
+
+type ctxKey struct{}
+
+func TestTaskContextValue(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithSignalHandling(false),
+		WithEntryCloseWait(20*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "trace-id")
+	err := p.Submit(NewTaskWithContext(ctx, func(ctx context.Context) error {
+		if v, _ := ctx.Value(ctxKey{}).(string); v != "trace-id" {
+			return fmt.Errorf("invalid context value: %v", v)
+		}
+
+		return nil
+	})).Wait(context.Background())
+	if err != nil {
+		t.Fatalf("the task should see the values of task context,err:%v", err)
+	}
+
+	p.Shutdown()
+	<-done
+}
+
+func TestShutdownDrain(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithSignalHandling(false),
+		WithEntryCap(10), WithWorkerCap(1),
+		WithEntryCloseWait(20*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	// the queued tasks are drained with the live root context after shutdown.
+	var drained int32
+	for i := 0; i < 3; i++ {
+		p.AddTask(NewTaskWithContext(context.Background(), func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			atomic.AddInt32(&drained, 1)
+			return nil
+		}))
+	}
+
+	if err := p.Shutdown(); err != nil {
+		t.Fatalf("shutdown error: %v", err)
+	}
+
+	<-done
+	if n := atomic.LoadInt32(&drained); n != 3 {
+		t.Fatalf("the queued tasks should be drained,drained:%d", n)
+	}
+
+	if s := p.Stats(); s.Failed != 0 {
+		t.Fatalf("the drained tasks should not fail,stats:%+v", s)
+	}
+}
+
 /**
 go test -v  -test.run TestPool
 2020/07/04 11:33:01 i =  937710
@@ -116,3 +315,33 @@ go test -v  -test.run TestShutdown
 2020/07/04 11:16:11 current worker id:  394 will exit...
 2020/07/04 11:16:11 current worker id:  14 will exit...
 */
+
+func TestTaskContextDeadline(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithSignalHandling(false),
+		WithEntryCloseWait(20*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	expect, _ := ctx.Deadline()
+	err := p.Submit(NewTaskWithContext(ctx, func(ctx context.Context) error {
+		if d, ok := ctx.Deadline(); !ok || !d.Equal(expect) {
+			return fmt.Errorf("invalid deadline: %v,%v", d, ok)
+		}
+
+		<-ctx.Done()
+		return ctx.Err()
+	})).Wait(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("the task should see the deadline of task context,err:%v", err)
+	}
+
+	p.Shutdown()
+	<-done
+}
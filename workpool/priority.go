@@ -0,0 +1,174 @@
+package workpool
+
+import (
+	"container/heap"
+	"time"
+)
+
+var (
+	// defaultPriorityAging the default aging interval of the priority queue.
+	defaultPriorityAging = time.Second
+
+	// defaultQueueCap the default max number of tasks in the priority queue
+	// when neither WithQueueCap,WithEntryCap nor WithJobCap is set.
+	defaultQueueCap = 64
+)
+
+// WithPriority set the task priority,the task with higher priority runs first.
+// The default priority is 0.
+func WithPriority(n int) TaskOption {
+	return func(t *Task) {
+		t.priority = n
+	}
+}
+
+// WithPriorityAging set the aging interval of the priority queue for starvation protection,
+// the priority of a waiting task increases by 1 every d, default 1s.
+// If d <= 0, the tasks are scheduled by priority strictly.
+func WithPriorityAging(d time.Duration) Option {
+	return func(p *Pool) {
+		p.priorityAging = d
+	}
+}
+
+// WithQueueCap set the max number of tasks waiting in the priority queue,
+// the tasks are ordered by priority only while they are in the queue.
+// The default is the entry chan cap plus the job cap,or 64 if both are 0.
+// When the queue is full,the scheduler stops receiving from the entry chan.
+func WithQueueCap(n int) Option {
+	return func(p *Pool) {
+		p.queueSize = n
+	}
+}
+
+// queueItem a task waiting in the priority queue.
+type queueItem struct {
+	task       *Task
+	seq        uint64    // the sequence of the task in the queue
+	enqueuedAt time.Time // the time of the task entered the queue
+}
+
+// taskQueue the priority queue of tasks,it implements heap.Interface.
+type taskQueue struct {
+	items []*queueItem
+	aging time.Duration
+}
+
+// Len implements heap.Interface.
+func (q *taskQueue) Len() int { return len(q.items) }
+
+// Less implements heap.Interface.
+func (q *taskQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if q.aging > 0 {
+		// The effective priority of a task is priority + waited/aging,
+		// comparing the effective priority at the same moment is the same as comparing
+		// enqueuedAt - priority*aging, so the order does not change over time.
+		ka := a.enqueuedAt.UnixNano() - int64(a.task.priority)*int64(q.aging)
+		kb := b.enqueuedAt.UnixNano() - int64(b.task.priority)*int64(q.aging)
+		if ka != kb {
+			return ka < kb
+		}
+
+		return a.seq < b.seq
+	}
+
+	if a.task.priority != b.task.priority {
+		return a.task.priority > b.task.priority
+	}
+
+	return a.seq < b.seq
+}
+
+// Swap implements heap.Interface.
+func (q *taskQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+// Push implements heap.Interface.
+func (q *taskQueue) Push(x interface{}) {
+	q.items = append(q.items, x.(*queueItem))
+}
+
+// Pop implements heap.Interface.
+func (q *taskQueue) Pop() interface{} {
+	n := len(q.items)
+	item := q.items[n-1]
+	q.items[n-1] = nil
+	q.items = q.items[:n-1]
+	return item
+}
+
+// queueCap returns the max number of tasks in the priority queue.
+func (p *Pool) queueCap() int {
+	if p.queueSize > 0 {
+		return p.queueSize
+	}
+
+	if n := p.entryCap + p.jobCap; n > 0 {
+		return n
+	}
+
+	return defaultQueueCap
+}
+
+// schedule move tasks from p.entryChan to p.jobChan by priority.
+// The jobChan will be closed after the entry chan is closed and all tasks are consumed.
+func (p *Pool) schedule() {
+	defer func() {
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+
+		close(p.jobChan)
+	}()
+
+	var (
+		seq uint64
+		in  = p.entryChan
+		q   = &taskQueue{aging: p.priorityAging}
+	)
+
+	for {
+		var (
+			recv = in
+			out  chan *Task
+			next *Task
+		)
+
+		if q.Len() > 0 {
+			out = p.jobChan
+			next = q.items[0].task
+
+			// hand off the task directly if a worker is ready,
+			// otherwise grow a worker when the backlog exceeds the scale threshold.
+			select {
+			case out <- next:
+				heap.Pop(q)
+				continue
+			default:
+				p.grow(q.Len())
+			}
+		}
+
+		if in == nil && next == nil {
+			return
+		}
+
+		// stop receiving when the queue is full,so the caller will be blocked.
+		if q.Len() >= p.queueCap() {
+			recv = nil
+		}
+
+		select {
+		case t, ok := <-recv:
+			if !ok {
+				in = nil
+				continue
+			}
+
+			seq++
+			heap.Push(q, &queueItem{task: t, seq: seq, enqueuedAt: time.Now()})
+		case out <- next:
+			heap.Pop(q)
+		}
+	}
+}
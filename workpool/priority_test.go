@@ -0,0 +1,168 @@
+package workpool
+
+import (
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runOrdered run tasks with one worker and returns the exec order of the tasks.
+// The worker is blocked until all tasks are added,so the tasks are scheduled by priority.
+func runOrdered(p *Pool, add func(record func(id int, opts ...TaskOption) *Task)) []int {
+	var (
+		mu    sync.Mutex
+		order []int
+	)
+
+	record := func(id int, opts ...TaskOption) *Task {
+		return NewTask(func() error {
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+			return nil
+		}, opts...)
+	}
+
+	release := make(chan struct{})
+	go func() {
+		p.AddTask(NewTask(func() error {
+			<-release
+			return nil
+		}, WithPriority(100)))
+
+		add(record)
+
+		// wait for the scheduler to receive all tasks from the entry chan.
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		p.Shutdown()
+	}()
+
+	p.Run()
+
+	return order
+}
+
+func TestPriority(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(100), WithWorkerCap(1),
+		WithPriorityAging(0),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	num := 90
+	order := runOrdered(p, func(record func(id int, opts ...TaskOption) *Task) {
+		for i := 0; i < num; i++ {
+			p.AddTask(record(i, WithPriority(i%3)))
+		}
+	})
+
+	if len(order) != num {
+		t.Fatalf("exec task num:%d,expect:%d", len(order), num)
+	}
+
+	for k := 1; k < len(order); k++ {
+		prev, cur := order[k-1], order[k]
+		// higher priority first,the same priority in FIFO order.
+		if prev%3 < cur%3 || (prev%3 == cur%3 && prev > cur) {
+			t.Fatalf("invalid exec order: %v", order)
+		}
+	}
+}
+
+func TestPriorityAging(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(100), WithWorkerCap(1),
+		WithPriorityAging(10*time.Millisecond),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	order := runOrdered(p, func(record func(id int, opts ...TaskOption) *Task) {
+		p.AddTask(record(0))
+
+		// the low priority task has waited for about 5 aging intervals.
+		time.Sleep(50 * time.Millisecond)
+		for i := 1; i <= 10; i++ {
+			p.AddTask(record(i, WithPriority(3)))
+		}
+	})
+
+	if len(order) != 11 || order[0] != 0 {
+		t.Fatalf("the low priority task should not be starved,exec order: %v", order)
+	}
+}
+
+func TestPriorityUnbufferedEntry(t *testing.T) {
+	// the entry chan is unbuffered,the tasks are still ordered in the priority queue.
+	p := NewPool(
+		WithExecInterval(0),
+		WithWorkerCap(1),
+		WithPriorityAging(0),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	order := runOrdered(p, func(record func(id int, opts ...TaskOption) *Task) {
+		for i := 0; i < 10; i++ {
+			p.AddTask(record(i, WithPriority(i)))
+		}
+	})
+
+	if len(order) != 10 {
+		t.Fatalf("exec task num:%d,expect:10", len(order))
+	}
+
+	for k, id := range order {
+		if id != 9-k {
+			t.Fatalf("invalid exec order: %v", order)
+		}
+	}
+}
+
+func TestPriorityJobCap(t *testing.T) {
+	// the tasks do not skip the priority queue by the job chan buffer.
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(10), WithJobCap(10), WithWorkerCap(1),
+		WithPriorityAging(0),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	order := runOrdered(p, func(record func(id int, opts ...TaskOption) *Task) {
+		for i := 0; i < 10; i++ {
+			p.AddTask(record(i, WithPriority(i)))
+		}
+	})
+
+	if len(order) != 10 {
+		t.Fatalf("exec task num:%d,expect:10", len(order))
+	}
+
+	for k, id := range order {
+		if id != 9-k {
+			t.Fatalf("invalid exec order: %v", order)
+		}
+	}
+}
+
+func TestQueueCap(t *testing.T) {
+	p := NewPool(WithEntryCap(100), WithQueueCap(8))
+	if n := p.queueCap(); n != 8 {
+		t.Fatalf("queue cap:%d,expect:8", n)
+	}
+
+	if n := NewPool(WithEntryCap(10), WithJobCap(5)).queueCap(); n != 15 {
+		t.Fatalf("queue cap:%d,expect:15", n)
+	}
+
+	if n := NewPool().queueCap(); n != defaultQueueCap {
+		t.Fatalf("queue cap:%d,expect:%d", n, defaultQueueCap)
+	}
+}
@@ -0,0 +1,155 @@
+package workpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limit the task exec rate of the pool by a token bucket shared across workers,
+// rps is the number of tasks per second,burst is the max number of tasks at once.
+// The WithExecInterval sleep can be disabled by WithExecInterval(0) if the rate limit is set.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(p *Pool) {
+		p.limiter = newLimiter(rps, burst)
+	}
+}
+
+// WithGroupRateLimit limit the task exec rate of each task group,
+// so that the tasks of one group can not exceed its quota.
+// Each group has its own token bucket with rps and burst.
+func WithGroupRateLimit(rps float64, burst int) Option {
+	return func(p *Pool) {
+		p.groupRate = rps
+		p.groupBurst = burst
+	}
+}
+
+// WithGroup set the task group,it is used by WithGroupRateLimit.
+func WithGroup(group string) TaskOption {
+	return func(t *Task) {
+		t.group = group
+	}
+}
+
+// limiter the token bucket rate limiter.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64 // bucket size
+	tokens float64 // current tokens,it is negative when tokens are reserved
+	last   time.Time
+}
+
+// newLimiter returns a token bucket limiter which is full.
+func newLimiter(rps float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &limiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve take a token and returns the wait time before the token is available.
+func (l *limiter) reserve() time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// release give back a reserved token.
+func (l *limiter) release() {
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
+
+// wait waits for a token until ctx or root is done.
+func (l *limiter) wait(ctx, root context.Context) error {
+	d := l.reserve()
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.release()
+		return ctx.Err()
+	case <-root.Done():
+		l.release()
+		return root.Err()
+	}
+}
+
+// groupLimiter returns the limiter of the task group,it is created lazily.
+func (p *Pool) groupLimiter(group string) *limiter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.groups == nil {
+		p.groups = make(map[string]*limiter)
+	}
+
+	l, ok := p.groups[group]
+	if !ok {
+		l = newLimiter(p.groupRate, p.groupBurst)
+		p.groups[group] = l
+	}
+
+	return l
+}
+
+// throttle waits for the group and pool rate limit before the task runs.
+// It returns the error if the task context or the pool root context is done while waiting,
+// then the task will be skipped and the group token is given back.
+func (p *Pool) throttle(t *Task) error {
+	var group *limiter
+	if t.group != "" && p.groupRate > 0 {
+		group = p.groupLimiter(t.group)
+		if err := group.wait(t.ctx, p.ctx); err != nil {
+			return err
+		}
+	}
+
+	if p.limiter == nil {
+		return nil
+	}
+
+	if err := p.limiter.wait(t.ctx, p.ctx); err != nil {
+		// the skipped task should not be charged to the group quota.
+		if group != nil {
+			group.release()
+		}
+
+		return err
+	}
+
+	return nil
+}
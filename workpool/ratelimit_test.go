@@ -0,0 +1,159 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(100), WithWorkerCap(10),
+		WithRateLimit(100, 1),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	start := time.Now()
+	futures := make([]*Future, 0, 20)
+	for i := 0; i < 20; i++ {
+		futures = append(futures, p.Submit(NewTask(func() error {
+			return nil
+		})))
+	}
+
+	for _, f := range futures {
+		<-f.Done()
+	}
+
+	// 20 tasks at 100 rps,the startPool task took the burst token.
+	if d := time.Since(start); d < 180*time.Millisecond {
+		t.Fatalf("the tasks exceed the rate limit,20 tasks in %v", d)
+	}
+
+	p.Shutdown()
+	<-done
+}
+
+func TestGroupRateLimit(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(100), WithWorkerCap(10),
+		WithGroupRateLimit(50, 1),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	var (
+		mu     sync.Mutex
+		finish = make(map[string]time.Time)
+	)
+
+	start := time.Now()
+	futures := make([]*Future, 0, 20)
+	for i := 0; i < 20; i++ {
+		group := "a"
+		if i%4 == 0 {
+			group = "b"
+		}
+
+		futures = append(futures, p.Submit(NewTask(func() error {
+			mu.Lock()
+			finish[group] = time.Now()
+			mu.Unlock()
+			return nil
+		}, WithGroup(group))))
+	}
+
+	for _, f := range futures {
+		<-f.Done()
+	}
+
+	// group a has 15 tasks and group b has 5 tasks at 50 rps for each group.
+	if d := finish["a"].Sub(start); d < 260*time.Millisecond {
+		t.Fatalf("group a exceed the rate limit,15 tasks in %v", d)
+	}
+
+	if !finish["b"].Before(finish["a"]) {
+		t.Fatalf("group b should not be limited by group a")
+	}
+
+	p.Shutdown()
+	<-done
+}
+
+func TestGroupRateLimitSkip(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(10), WithWorkerCap(2),
+		WithGroupRateLimit(1, 1),
+		WithEntryCloseWait(20*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	// the first task takes the token,the second one waits about 1s for the group limiter.
+	<-p.Submit(NewTask(func() error {
+		return nil
+	}, WithGroup("a"))).Done()
+
+	var ran int32
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := p.Submit(NewTaskWithContext(ctx, func(ctx context.Context) error {
+		atomic.StoreInt32(&ran, 1)
+		return nil
+	}, WithGroup("a"))).Wait(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("the task should be skipped while waiting for the limiter,err:%v", err)
+	}
+
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Fatal("the skipped task should not run")
+	}
+
+	if s := p.Stats(); s.Skipped != 1 {
+		t.Fatalf("the task should be counted as skipped,stats:%+v", s)
+	}
+
+	p.Shutdown()
+	<-done
+}
+
+func TestThrottleReleaseGroupToken(t *testing.T) {
+	p := NewPool(
+		WithRateLimit(1, 1),
+		WithGroupRateLimit(1, 1),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	// the pool token is taken,the task waits about 1s for the pool limiter.
+	p.limiter.reserve()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	task := NewTaskWithContext(ctx, func(ctx context.Context) error {
+		return nil
+	}, WithGroup("a"))
+	if err := p.throttle(task); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("the pool limiter wait should be canceled,err:%v", err)
+	}
+
+	// the group token taken by the skipped task is given back.
+	if d := p.groupLimiter("a").reserve(); d > 0 {
+		t.Fatalf("the group token should be released,wait:%v", d)
+	}
+}
@@ -0,0 +1,71 @@
+package workpool
+
+import (
+	"encoding/json"
+
+	"github.com/go-redis/redis"
+)
+
+var _ Store = (*RedisStore)(nil)
+
+// RedisStore the redis list implementation of Store,
+// the client can be created by goredis.RedisClientConf GetClient.
+type RedisStore struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisStore returns a redis store,the records are saved in the list of key.
+func NewRedisStore(client *redis.Client, key string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		key:    key,
+	}
+}
+
+// Save implements Store.
+func (s *RedisStore) Save(r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	r.raw = string(b)
+	return s.client.LPush(s.key, r.raw).Err()
+}
+
+// Ack implements Store.
+func (s *RedisStore) Ack(r *Record) error {
+	raw := r.raw
+	if raw == "" {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+
+		raw = string(b)
+	}
+
+	return s.client.LRem(s.key, 1, raw).Err()
+}
+
+// Load implements Store,the records are returned by save order.
+func (s *RedisStore) Load() ([]*Record, error) {
+	values, err := s.client.LRange(s.key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*Record, 0, len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		r := &Record{}
+		if err = json.Unmarshal([]byte(values[i]), r); err != nil {
+			return nil, err
+		}
+
+		r.raw = values[i]
+		records = append(records, r)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,151 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrPoolFull the task entry chan is full and the task is rejected.
+	ErrPoolFull = errors.New("work pool is full")
+
+	// ErrTaskDropped the task is dropped by the RejectDropOldest policy.
+	ErrTaskDropped = errors.New("task dropped")
+)
+
+// RejectPolicy the policy to handle a task when the task entry chan is full.
+type RejectPolicy int
+
+const (
+	// RejectBlock wait until the task is added to the entry chan,it is the default policy.
+	RejectBlock RejectPolicy = iota
+
+	// RejectDrop drop the task and returns ErrPoolFull.
+	RejectDrop
+
+	// RejectDropOldest drop the oldest task in the entry chan and add the task,
+	// the future of the dropped task is finished with ErrTaskDropped.
+	RejectDropOldest
+
+	// RejectCallerRuns run the task in the caller goroutine.
+	RejectCallerRuns
+)
+
+// WithRejectPolicy change the reject policy when the task entry chan is full,
+// default RejectBlock.
+func WithRejectPolicy(policy RejectPolicy) Option {
+	return func(p *Pool) {
+		p.rejectPolicy = policy
+	}
+}
+
+// TryAddTask add a task to p.entryChan without blocking.
+// It returns ErrPoolFull if the entry chan is full and the task is rejected,
+// for the RejectBlock policy, the task is rejected immediately.
+func (p *Pool) TryAddTask(t *Task) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	return p.AddTaskWithTimeout(ctx, t)
+}
+
+// AddTaskWithTimeout add a task to p.entryChan,
+// for the RejectBlock policy, it waits until the task is added or ctx is done,
+// ErrPoolFull will be returned if ctx is done.
+func (p *Pool) AddTaskWithTimeout(ctx context.Context, t *Task) error {
+	if t == nil {
+		return ErrTaskInvalid
+	}
+
+	return p.offer(ctx, t.clone())
+}
+
+// offer send a task to p.entryChan,if the entry chan is full,
+// the task will be handled by the reject policy.
+func (p *Pool) offer(ctx context.Context, t *Task) (err error) {
+	// the retried task is not counted as submitted,
+	// read it before sending, the task is owned by the worker after sent.
+	retried := t.attempts > 0
+
+	// count the task as queued before sending,
+	// so that the queued counter never be negative when the worker receives it.
+	p.counters.queued.Add(1)
+	defer func() {
+		if err != nil {
+			p.counters.queued.Add(-1)
+		}
+
+		switch {
+		case err == nil && !retried:
+			p.counters.submitted.Add(1)
+		case err == ErrPoolFull:
+			p.counters.rejected.Add(1)
+		}
+	}()
+
+	defer func() {
+		if e := recover(); e != nil {
+			// the entry chan is closed.
+			p.logEntry.Println("exec panic: ", e)
+			err = ErrPoolClosed
+		}
+	}()
+
+	select {
+	case <-p.stop:
+		return ErrPoolClosed
+	default:
+	}
+
+	if !retried {
+		replayed := t.record != nil
+		if err = p.persist(t); err != nil {
+			return err
+		}
+
+		// the task journaled by this call is removed if it is not accepted.
+		defer func() {
+			if err != nil && !replayed && t.record != nil {
+				if e := p.store.Ack(t.record); e != nil {
+					p.logEntry.Println("ack task record error: ", e)
+				}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case p.entryChan <- t:
+			return nil
+		default:
+		}
+
+		switch p.rejectPolicy {
+		case RejectDrop:
+			return ErrPoolFull
+		case RejectDropOldest:
+			select {
+			case old := <-p.entryChan:
+				p.logEntry.Println("entry chan is full,drop the oldest task")
+				p.counters.queued.Add(-1)
+				p.counters.rejected.Add(1)
+				p.settle(old, ErrTaskDropped)
+				continue
+			default:
+				// no task to drop,wait for the entry chan.
+			}
+		case RejectCallerRuns:
+			// the task runs in the caller goroutine,it is not queued.
+			p.counters.queued.Add(-1)
+			p.process(t)
+			return nil
+		}
+
+		select {
+		case p.entryChan <- t:
+			return nil
+		case <-ctx.Done():
+			return ErrPoolFull
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+// newFullPool returns a pool which is not running and its entry chan is full.
+func newFullPool(policy RejectPolicy) (*Pool, []*Future) {
+	p := NewPool(
+		WithEntryCap(2),
+		WithRejectPolicy(policy),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	futures := make([]*Future, 0, 2)
+	for i := 0; i < 2; i++ {
+		futures = append(futures, p.Submit(NewTask(func() error {
+			return nil
+		})))
+	}
+
+	return p, futures
+}
+
+func TestRejectBlock(t *testing.T) {
+	p, _ := newFullPool(RejectBlock)
+	task := NewTask(func() error {
+		return nil
+	})
+
+	if err := p.TryAddTask(task); !errors.Is(err, ErrPoolFull) {
+		t.Fatalf("try add task to full pool should return ErrPoolFull,got err:%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := p.AddTaskWithTimeout(ctx, task); !errors.Is(err, ErrPoolFull) {
+		t.Fatalf("add task to full pool with timeout should return ErrPoolFull,got err:%v", err)
+	}
+}
+
+func TestRejectDrop(t *testing.T) {
+	p, _ := newFullPool(RejectDrop)
+	f := p.Submit(NewTask(func() error {
+		return nil
+	}))
+
+	if err := f.Wait(context.Background()); !errors.Is(err, ErrPoolFull) {
+		t.Fatalf("the dropped task should return ErrPoolFull,got err:%v", err)
+	}
+}
+
+func TestRejectDropOldest(t *testing.T) {
+	p, futures := newFullPool(RejectDropOldest)
+	if err := p.TryAddTask(NewTask(func() error {
+		return nil
+	})); err != nil {
+		t.Fatalf("try add task with drop oldest policy error:%v", err)
+	}
+
+	if err := futures[0].Wait(context.Background()); !errors.Is(err, ErrTaskDropped) {
+		t.Fatalf("the oldest task should be dropped,got err:%v", err)
+	}
+
+	select {
+	case <-futures[1].Done():
+		t.Fatal("only the oldest task should be dropped")
+	default:
+	}
+}
+
+func TestRejectCallerRuns(t *testing.T) {
+	p, _ := newFullPool(RejectCallerRuns)
+	var ran bool
+	err := p.TryAddTask(NewTask(func() error {
+		ran = true
+		return nil
+	}))
+
+	if err != nil || !ran {
+		t.Fatalf("the task should run in the caller goroutine,ran:%v err:%v", ran, err)
+	}
+}
@@ -0,0 +1,146 @@
+package workpool
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy the retry policy of the failed task.
+type RetryPolicy struct {
+	// MaxAttempts the max exec times of a task,including the first one.
+	// If MaxAttempts <= 1, the task will not be retried.
+	MaxAttempts int
+
+	// InitialBackoff the wait time before the first retry,default 100ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff the max wait time before a retry,default 10s.
+	MaxBackoff time.Duration
+
+	// Multiplier the backoff is multiplied by it after each retry,default 2.
+	Multiplier float64
+
+	// Jitter randomize the backoff by ±Jitter ratio,it should be in [0,1].
+	Jitter float64
+
+	// Retryable returns true if the task error is retryable,
+	// if it is nil, all the task errors including panic are retryable.
+	Retryable func(err error) bool
+}
+
+// backoff returns the wait time before the nth retry.
+func (r *RetryPolicy) backoff(n int) time.Duration {
+	d := float64(r.InitialBackoff)
+	if d <= 0 {
+		d = float64(100 * time.Millisecond)
+	}
+
+	max := float64(r.MaxBackoff)
+	if max <= 0 {
+		max = float64(10 * time.Second)
+	}
+
+	multiplier := r.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	for i := 1; i < n && d < max; i++ {
+		d *= multiplier
+	}
+
+	if d > max {
+		d = max
+	}
+
+	if r.Jitter > 0 {
+		d += d * r.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(d)
+}
+
+// retryable returns true if the task can be retried.
+func (r *RetryPolicy) retryable(attempts int, err error) bool {
+	if attempts >= r.MaxAttempts {
+		return false
+	}
+
+	return r.Retryable == nil || r.Retryable(err)
+}
+
+// WithRetry set the retry policy of the task,it overrides the pool retry policy.
+func WithRetry(policy *RetryPolicy) TaskOption {
+	return func(t *Task) {
+		t.retry = policy
+	}
+}
+
+// WithRetryPolicy set the retry policy for all tasks of the pool.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(p *Pool) {
+		p.retryPolicy = policy
+	}
+}
+
+// WithDeadLetter set the dead letter func,it is called when a task finally fails,
+// that is, the task error is not retryable or the max attempts is reached.
+func WithDeadLetter(fn func(t *Task, err error)) Option {
+	return func(p *Pool) {
+		p.deadLetter = fn
+	}
+}
+
+// Attempts returns the number of exec times of the task.
+func (t *Task) Attempts() int {
+	return t.attempts
+}
+
+// retry re-enqueue the failed task after backoff,returns false if the task can not be retried.
+func (p *Pool) retry(t *Task, status taskStatus, err error) bool {
+	if status != taskFailed && status != taskPanicked {
+		return false
+	}
+
+	policy := t.retry
+	if policy == nil {
+		policy = p.retryPolicy
+	}
+
+	if policy == nil || !policy.retryable(t.attempts, err) || t.ctx.Err() != nil {
+		return false
+	}
+
+	select {
+	case <-p.stop:
+		// the pool is shutting down,the task can not be re-enqueued.
+		return false
+	default:
+	}
+
+	d := policy.backoff(t.attempts)
+	p.counters.retried.Add(1)
+	p.logEntry.Println("task attempts: ", t.attempts, "retry after: ", d)
+
+	time.AfterFunc(d, func() {
+		if e := p.offer(context.Background(), t); e != nil {
+			p.logEntry.Println("retry task error: ", e)
+			p.fail(t, err)
+			p.settle(t, err)
+		}
+	})
+
+	return true
+}
+
+// fail call the dead letter func with the final task error.
+func (p *Pool) fail(t *Task, err error) {
+	if p.deadLetter == nil {
+		return
+	}
+
+	defer p.recovery()
+
+	p.deadLetter(t, err)
+}
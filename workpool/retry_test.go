@@ -0,0 +1,100 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	var (
+		deadAttempts int32
+		deadErr      atomic.Value
+	)
+
+	mockErr := errors.New("mock timeout")
+	fatalErr := errors.New("mock fatal error")
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(10), WithWorkerCap(2),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithRetryPolicy(&RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			Jitter:         0.2,
+			Retryable: func(err error) bool {
+				return errors.Is(err, mockErr)
+			},
+		}),
+		WithDeadLetter(func(task *Task, err error) {
+			atomic.StoreInt32(&deadAttempts, int32(task.Attempts()))
+			deadErr.Store(err)
+		}),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+	ctx := context.Background()
+
+	// the task succeeds at the third attempt.
+	var calls int32
+	f := p.Submit(NewTask(func() error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return mockErr
+		}
+
+		return nil
+	}))
+	if err := f.Wait(ctx); err != nil || atomic.LoadInt32(&calls) != 3 {
+		t.Fatalf("the task should succeed after retry,calls:%d err:%v", calls, err)
+	}
+
+	// the task always fails,it goes to the dead letter after 3 attempts.
+	f = p.Submit(NewTask(func() error {
+		return mockErr
+	}))
+	if err := f.Wait(ctx); !errors.Is(err, mockErr) {
+		t.Fatalf("the task should fail after max attempts,err:%v", err)
+	}
+
+	if n := atomic.LoadInt32(&deadAttempts); n != 3 || deadErr.Load() != mockErr {
+		t.Fatalf("the dead letter should receive the task after 3 attempts,attempts:%d", n)
+	}
+
+	// the task error is not retryable,the task policy overrides the pool policy.
+	calls = 0
+	f = p.Submit(NewTask(func() error {
+		atomic.AddInt32(&calls, 1)
+		return fatalErr
+	}, WithRetry(&RetryPolicy{MaxAttempts: 5})))
+	if err := f.Wait(ctx); !errors.Is(err, fatalErr) || atomic.LoadInt32(&calls) != 5 {
+		t.Fatalf("the task should be retried by task policy,calls:%d err:%v", calls, err)
+	}
+
+	if s := p.Stats(); s.Retried != 2+2+4 {
+		t.Fatalf("invalid retried count: %+v", s)
+	}
+
+	p.Shutdown()
+	<-done
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+	}
+
+	expects := []time.Duration{
+		10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond,
+	}
+	for i, expect := range expects {
+		if d := policy.backoff(i + 1); d != expect {
+			t.Fatalf("retry %d backoff:%v,expect:%v", i+1, d, expect)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package workpool
+
+import (
+	"time"
+)
+
+// defaultIdleTimeout the default idle time before an extra worker exit.
+var defaultIdleTimeout = time.Minute
+
+// WithMinWorkers set the min worker num,the pool starts with n workers
+// and keeps at least n workers alive. The default is the worker cap.
+func WithMinWorkers(n int) Option {
+	return func(p *Pool) {
+		p.minWorkers = n
+	}
+}
+
+// WithMaxWorkers set the max worker num,the pool grows workers up to n
+// when the task backlog exceeds the scale threshold.
+// The default is the min worker num,that is, no autoscaling.
+func WithMaxWorkers(n int) Option {
+	return func(p *Pool) {
+		p.maxWorkers = n
+	}
+}
+
+// WithScaleThreshold grow a worker when the number of waiting tasks exceeds n
+// and no worker is ready to receive the task,default 0.
+func WithScaleThreshold(n int) Option {
+	return func(p *Pool) {
+		p.scaleThreshold = n
+	}
+}
+
+// WithIdleTimeout set the idle time before an extra worker exit,default 1min.
+// The pool keeps at least min workers alive,if d <= 0, the idle workers never exit.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(p *Pool) {
+		p.idleTimeout = d
+	}
+}
+
+// Resize change the worker num to n at runtime,n is also used as the min worker num.
+// If the max worker num is less than n,it will be raised to n.
+func (p *Pool) Resize(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	if n > defaultMaxWorker {
+		n = defaultMaxWorker
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.minWorkers = n
+	if p.maxWorkers < n {
+		p.maxWorkers = n
+	}
+
+	if !p.running || p.closed {
+		return
+	}
+
+	for p.workerNum < n {
+		p.spawn()
+	}
+
+	// the retire chan has enough buf,the retired workers are not counted.
+	for p.workerNum > n {
+		p.workerNum--
+		p.retire <- est
+	}
+}
+
+// Workers returns the current worker num.
+func (p *Pool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.workerNum
+}
+
+// spawn start a new worker,p.mu must be held.
+func (p *Pool) spawn() {
+	p.workerSeq++
+	p.workerNum++
+	p.wg.Add(1)
+	go p.exec(p.workerSeq)
+}
+
+// grow start a new worker when the backlog exceeds the scale threshold
+// and the worker num is less than the max worker num.
+func (p *Pool) grow(backlog int) {
+	if backlog <= p.scaleThreshold {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.workerNum >= p.maxWorkers {
+		return
+	}
+
+	p.logEntry.Println("task backlog: ", backlog, "grow a worker")
+	p.spawn()
+}
+
+// retireIdle returns true if the idle worker can exit.
+func (p *Pool) retireIdle() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.workerNum <= p.minWorkers {
+		return false
+	}
+
+	p.workerNum--
+	return true
+}
+
+// resetTimer stop the timer and reset it to d.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+
+	t.Reset(d)
+}
@@ -0,0 +1,80 @@
+package workpool
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAutoScale(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(100),
+		WithMinWorkers(1), WithMaxWorkers(5),
+		WithIdleTimeout(100*time.Millisecond),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	futures := make([]*Future, 0, 50)
+	for i := 0; i < 50; i++ {
+		futures = append(futures, p.Submit(NewTask(func() error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})))
+	}
+
+	var maxWorkers int
+	for _, f := range futures {
+		if n := p.Workers(); n > maxWorkers {
+			maxWorkers = n
+		}
+
+		<-f.Done()
+	}
+
+	if maxWorkers <= 1 || maxWorkers > 5 {
+		t.Fatalf("the pool should grow workers under load,max workers: %d", maxWorkers)
+	}
+
+	// the extra workers exit after idle timeout.
+	time.Sleep(300 * time.Millisecond)
+	if n := p.Workers(); n != 1 {
+		t.Fatalf("the idle workers should exit,workers: %d", n)
+	}
+
+	p.Shutdown()
+	<-done
+}
+
+func TestResize(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithWorkerCap(2),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+
+	p.Resize(6)
+	if n := p.Workers(); n != 6 {
+		t.Fatalf("resize workers to 6,got: %d", n)
+	}
+
+	p.Resize(1)
+	if n := p.Workers(); n != 1 {
+		t.Fatalf("resize workers to 1,got: %d", n)
+	}
+
+	f := p.Submit(NewTask(func() error {
+		return nil
+	}))
+	<-f.Done()
+
+	p.Shutdown()
+	<-done
+}
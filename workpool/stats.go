@@ -0,0 +1,129 @@
+package workpool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// taskStatus the exec status of a task.
+type taskStatus int
+
+const (
+	// taskSucceeded the task returns nil.
+	taskSucceeded taskStatus = iota
+
+	// taskFailed the task returns an error.
+	taskFailed
+
+	// taskPanicked the task throw a panic.
+	taskPanicked
+
+	// taskSkipped the task context is done before it runs.
+	taskSkipped
+)
+
+// Stats the statistics snapshot of the work pool.
+type Stats struct {
+	Queued    int64 // the number of tasks waiting to run
+	Running   int64 // the number of running tasks,that is, the active workers
+	Workers   int   // the number of workers
+	Submitted int64 // the total number of tasks added to the pool
+	Rejected  int64 // the total number of tasks rejected or dropped by the reject policy
+	Succeeded int64 // the total number of tasks succeeded
+	Failed    int64 // the total number of tasks returned an error
+	Panicked  int64 // the total number of tasks panicked
+	Skipped   int64 // the total number of tasks skipped because the task context is done
+	Retried   int64 // the total number of retries
+}
+
+// counters the pool counters for statistics.
+type counters struct {
+	queued    atomic.Int64
+	running   atomic.Int64
+	submitted atomic.Int64
+	rejected  atomic.Int64
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	panicked  atomic.Int64
+	skipped   atomic.Int64
+	retried   atomic.Int64
+}
+
+// record count the task by status.
+func (c *counters) record(status taskStatus) {
+	switch status {
+	case taskSucceeded:
+		c.succeeded.Add(1)
+	case taskFailed:
+		c.failed.Add(1)
+	case taskPanicked:
+		c.panicked.Add(1)
+	case taskSkipped:
+		c.skipped.Add(1)
+	}
+}
+
+// taskObserver observe the status and exec duration of each task.
+type taskObserver func(status taskStatus, d time.Duration)
+
+// Stats returns the statistics snapshot of the pool.
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Queued:    p.counters.queued.Load(),
+		Running:   p.counters.running.Load(),
+		Workers:   p.Workers(),
+		Submitted: p.counters.submitted.Load(),
+		Rejected:  p.counters.rejected.Load(),
+		Succeeded: p.counters.succeeded.Load(),
+		Failed:    p.counters.failed.Load(),
+		Panicked:  p.counters.panicked.Load(),
+		Skipped:   p.counters.skipped.Load(),
+		Retried:   p.counters.retried.Load(),
+	}
+}
+
+// addObserver add a task observer to the pool.
+func (p *Pool) addObserver(o taskObserver) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// copy on write,so the observers can be read without lock.
+	old, _ := p.observers.Load().([]taskObserver)
+	observers := make([]taskObserver, 0, len(old)+1)
+	observers = append(observers, old...)
+	observers = append(observers, o)
+	p.observers.Store(observers)
+}
+
+// process run a task,record the task result and finish the task future.
+// The failed task will be retried by the retry policy.
+func (p *Pool) process(t *Task) {
+	var d time.Duration
+	status, err := taskSkipped, p.throttle(t)
+	if err == nil {
+		p.counters.running.Add(1)
+		start := time.Now()
+		t.attempts++
+		status, err = t.run(p.ctx, p.logEntry)
+		d = time.Since(start)
+		p.counters.running.Add(-1)
+	} else {
+		p.logEntry.Println("skip task,rate limit wait: ", err)
+	}
+
+	p.counters.record(status)
+	observers, _ := p.observers.Load().([]taskObserver)
+	for _, o := range observers {
+		o(status, d)
+	}
+
+	if p.retry(t, status, err) {
+		return
+	}
+
+	if status == taskFailed || status == taskPanicked {
+		p.fail(t, err)
+	}
+
+	p.settle(t, err)
+}
@@ -0,0 +1,87 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestStats(t *testing.T) {
+	p := NewPool(
+		WithExecInterval(0),
+		WithEntryCap(10), WithWorkerCap(2),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewCollector(p, "test"))
+
+	done := startPool(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	futures := []*Future{
+		p.Submit(NewTask(func() error {
+			return nil
+		})),
+		p.Submit(NewTask(func() error {
+			return errors.New("mock error")
+		})),
+		p.Submit(NewTask(func() error {
+			panic("mock panic")
+		})),
+		p.Submit(NewTaskWithContext(ctx, func(ctx context.Context) error {
+			return nil
+		})),
+	}
+
+	for _, f := range futures {
+		<-f.Done()
+	}
+
+	// the startPool task is counted.
+	s := p.Stats()
+	if s.Submitted != 5 || s.Succeeded != 2 || s.Failed != 1 || s.Panicked != 1 || s.Skipped != 1 {
+		t.Fatalf("invalid stats: %+v", s)
+	}
+
+	if s.Queued != 0 || s.Running != 0 || s.Workers != 2 {
+		t.Fatalf("invalid stats: %+v", s)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics error:%v", err)
+	}
+
+	metrics := make(map[string]bool, len(families))
+	for _, mf := range families {
+		metrics[mf.GetName()] = true
+		if mf.GetName() == "workpool_task_panics_total" && mf.GetMetric()[0].GetCounter().GetValue() != 1 {
+			t.Fatalf("invalid panic count: %v", mf.GetMetric()[0])
+		}
+
+		if mf.GetName() == "workpool_task_duration_seconds" && mf.GetMetric()[0].GetHistogram().GetSampleCount() != 4 {
+			t.Fatalf("invalid task duration sample count: %v", mf.GetMetric()[0])
+		}
+	}
+
+	for _, name := range []string{
+		"workpool_queue_depth", "workpool_workers", "workpool_active_workers",
+		"workpool_tasks_total", "workpool_task_panics_total", "workpool_task_duration_seconds",
+	} {
+		if !metrics[name] {
+			t.Fatalf("metric %s not found", name)
+		}
+	}
+
+	p.Shutdown()
+	<-done
+}
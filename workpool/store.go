@@ -0,0 +1,151 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrHandlerNotFound the handler of the named task is not registered.
+var ErrHandlerNotFound = errors.New("task handler not found")
+
+// Record the serializable task record in the store.
+type Record struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Payload   []byte    `json:"payload"`
+	Priority  int       `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+
+	raw string // the raw data in the store
+}
+
+// Store the durable backend of the named tasks,
+// the named tasks are journaled before execution and replayed on Run after restart.
+type Store interface {
+	// Save journal the record before the task is executed.
+	Save(r *Record) error
+
+	// Ack remove the record after the task is finished.
+	Ack(r *Record) error
+
+	// Load returns the records which are not acked.
+	Load() ([]*Record, error)
+}
+
+// Handler the handler func of the named task.
+type Handler func(ctx context.Context, payload []byte) error
+
+// WithStore set the durable store of the named tasks.
+// The records not acked in the store are loaded when the pool created,
+// and replayed when the pool runs,the load error is returned by LoadError.
+func WithStore(s Store) Option {
+	return func(p *Pool) {
+		p.store = s
+	}
+}
+
+// WithHandler register the handler of the named task.
+func WithHandler(name string, h Handler) Option {
+	return func(p *Pool) {
+		if p.handlers == nil {
+			p.handlers = make(map[string]Handler)
+		}
+
+		p.handlers[name] = h
+	}
+}
+
+// NewNamedTask returns a serializable task,it is exec by the handler registered by WithHandler.
+// If the pool has a store, the task is journaled before execution.
+func NewNamedTask(name string, payload []byte, opts ...TaskOption) *Task {
+	t := &Task{
+		ctx:     context.Background(),
+		name:    name,
+		payload: payload,
+	}
+
+	for _, o := range opts {
+		o(t)
+	}
+
+	return t
+}
+
+// persist bind the handler of the named task and journal it to the store.
+func (p *Pool) persist(t *Task) error {
+	if t.name == "" {
+		return nil
+	}
+
+	h, ok := p.handlers[t.name]
+	if !ok {
+		return ErrHandlerNotFound
+	}
+
+	payload := t.payload
+	t.fn = func(ctx context.Context) error {
+		return h(ctx, payload)
+	}
+
+	// the replayed task has been journaled.
+	if p.store == nil || t.record != nil {
+		return nil
+	}
+
+	t.record = &Record{
+		ID:        uuid.NewString(),
+		Name:      t.name,
+		Payload:   t.payload,
+		Priority:  t.priority,
+		CreatedAt: time.Now(),
+	}
+
+	return p.store.Save(t.record)
+}
+
+// settle remove the task record from the store and finish the task future.
+func (p *Pool) settle(t *Task, err error) {
+	if t.record != nil && p.store != nil {
+		if e := p.store.Ack(t.record); e != nil {
+			p.logEntry.Println("ack task record error: ", e)
+		}
+	}
+
+	t.finish(err)
+}
+
+// LoadError returns the error of loading the records from the store when the pool created.
+func (p *Pool) LoadError() error {
+	return p.loadErr
+}
+
+// load returns the records not acked in the store.
+func (p *Pool) load() ([]*Record, error) {
+	if p.store == nil {
+		return nil, nil
+	}
+
+	records, err := p.store.Load()
+	if err != nil {
+		p.logEntry.Println("load task records error: ", err)
+	}
+
+	return records, err
+}
+
+// replay re-enqueue the records loaded from the store.
+func (p *Pool) replay() {
+	records := p.pending
+	p.pending = nil
+
+	for _, r := range records {
+		t := NewNamedTask(r.Name, r.Payload, WithPriority(r.Priority))
+		t.record = r
+		if err := p.offer(context.Background(), t); err != nil {
+			p.logEntry.Println("replay task error: ", err, "record id: ", r.ID)
+		}
+	}
+}
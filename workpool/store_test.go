@@ -0,0 +1,178 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// testReplay add named tasks to a pool which is not running to mock a crash,
+// then the tasks will be replayed by a new pool with the same store.
+func testReplay(t *testing.T, newStore func() Store) {
+	var (
+		mu       sync.Mutex
+		payloads []string
+	)
+
+	handler := func(ctx context.Context, payload []byte) error {
+		mu.Lock()
+		payloads = append(payloads, string(payload))
+		mu.Unlock()
+		return nil
+	}
+
+	p := NewPool(
+		WithEntryCap(10),
+		WithStore(newStore()),
+		WithHandler("send_email", handler),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	p.AddTask(NewNamedTask("send_email", []byte("a@example.com")))
+	p.AddTask(NewNamedTask("send_email", []byte("b@example.com")))
+	if err := p.TryAddTask(NewNamedTask("unknown", nil)); err != ErrHandlerNotFound {
+		t.Fatalf("add task without handler should return ErrHandlerNotFound,got err:%v", err)
+	}
+
+	// the pool crashed,the tasks are replayed by the new pool.
+	store := newStore()
+	p = NewPool(
+		WithExecInterval(0),
+		WithEntryCap(10), WithWorkerCap(1),
+		WithStore(store),
+		WithHandler("send_email", handler),
+		WithEntryCloseWait(100*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	done := startPool(p)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(payloads)
+		mu.Unlock()
+		if n == 2 && p.Stats().Running == 0 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	p.Shutdown()
+	<-done
+
+	if len(payloads) != 2 || payloads[0] != "a@example.com" || payloads[1] != "b@example.com" {
+		t.Fatalf("the tasks should be replayed,payloads: %v", payloads)
+	}
+
+	records, err := store.Load()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("the finished tasks should be acked,records:%d err:%v", len(records), err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workpool.journal")
+	testReplay(t, func() Store {
+		s, err := NewFileStore(path)
+		if err != nil {
+			t.Fatalf("create file store error:%v", err)
+		}
+
+		t.Cleanup(func() {
+			s.Close()
+		})
+
+		return s
+	})
+}
+
+func TestRedisStore(t *testing.T) {
+	client := redis.NewClient(&redis.Options{
+		Addr: "127.0.0.1:6379",
+	})
+	if err := client.Ping().Err(); err != nil {
+		t.Skipf("redis is not available:%v", err)
+	}
+
+	key := "workpool:test:tasks"
+	client.Del(key)
+	defer client.Del(key)
+
+	testReplay(t, func() Store {
+		return NewRedisStore(client, key)
+	})
+}
+
+// failStore the store which fails to load the records.
+type failStore struct {
+	loads int32
+}
+
+var errLoad = errors.New("load records error")
+
+func (s *failStore) Save(r *Record) error { return nil }
+
+func (s *failStore) Ack(r *Record) error { return nil }
+
+func (s *failStore) Load() ([]*Record, error) {
+	atomic.AddInt32(&s.loads, 1)
+	return nil, errLoad
+}
+
+func TestStoreLoadError(t *testing.T) {
+	store := &failStore{}
+	p := NewPool(WithStore(store))
+
+	// the records are loaded once when the pool created.
+	if n := atomic.LoadInt32(&store.loads); n != 1 {
+		t.Fatalf("the store should be loaded by NewPool,loads:%d", n)
+	}
+
+	if err := p.LoadError(); !errors.Is(err, errLoad) {
+		t.Fatalf("the load error should be exposed,err:%v", err)
+	}
+}
+
+func TestStoreAddBeforeRun(t *testing.T) {
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "workpool.journal"))
+	if err != nil {
+		t.Fatalf("create file store error:%v", err)
+	}
+
+	defer s.Close()
+
+	var count int32
+	p := NewPool(
+		WithExecInterval(0),
+		WithSignalHandling(false),
+		WithEntryCap(10),
+		WithStore(s),
+		WithHandler("h", func(ctx context.Context, payload []byte) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		}),
+		WithEntryCloseWait(50*time.Millisecond),
+		WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	// the task journaled before Run should not be replayed by the same pool.
+	f := p.Submit(NewNamedTask("h", nil))
+	done := startPool(p)
+	<-f.Done()
+
+	p.Shutdown()
+	<-done
+
+	if n := atomic.LoadInt32(&count); n != 1 {
+		t.Fatalf("the task added before Run should run once,count:%d", n)
+	}
+}